@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// aliasTypes lists the alias types that can be created through the API.
+// "internal" aliases are maintained by OPNsense itself and are left out. That
+// includes the interface networks (__lan_network, __opt1_network, ...), which
+// are referenced by name from network and networkgroup content instead.
+var aliasTypes = []string{
+	"host", "network", "port", "url", "urltable", "urljson", "geoip",
+	"networkgroup", "mac", "asn", "dynipv6host", "authgroup", "external",
+}
+
+var (
+	// Alias names: letters, digits and underscores, at most 31 characters.
+	aliasNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_]{1,31}$`)
+
+	// ISO 3166-1 alpha-2 country codes as used by geoip aliases.
+	countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+	// Full or partial (vendor prefix) MAC addresses.
+	macPattern = regexp.MustCompile(`^[0-9a-fA-F]{2}([:-][0-9a-fA-F]{2}){0,5}$`)
+
+	// Autonomous system numbers, with or without the "AS" prefix.
+	asnPattern = regexp.MustCompile(`^(?i:AS)?[0-9]{1,10}$`)
+
+	// Host names and FQDNs resolved by host aliases.
+	hostnamePattern = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.?$`)
+
+	// OpenVPN group names.
+	groupNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+)
+
+// aliasTypeFields lists, per optional attribute, the alias types it applies to.
+var aliasTypeFields = map[string][]string{
+	"proto":           {"geoip", "asn"},
+	"update_freq":     {"urltable", "urljson"},
+	"interface":       {"dynipv6host"},
+	"path_expression": {"urljson"},
+	"auth_type":       {"url", "urltable", "urljson"},
+	"username":        {"url", "urltable", "urljson"},
+	"password":        {"url", "urltable", "urljson"},
+}
+
+// validateAliasEntry checks a single content entry against the alias type,
+// returning a description of the problem or "" when the entry is valid.
+func validateAliasEntry(aliasType, entry string) string {
+	switch aliasType {
+	case "host":
+		if isAliasAddress(strings.TrimPrefix(entry, "!")) || hostnamePattern.MatchString(entry) || aliasNamePattern.MatchString(entry) {
+			return ""
+		}
+		return "expected an IP address, address range, host name or alias name"
+	case "network":
+		if isAliasNetwork(strings.TrimPrefix(entry, "!")) || aliasNamePattern.MatchString(entry) {
+			return ""
+		}
+		return "expected a network in CIDR notation, an IP address, an address range or an alias name"
+	case "port":
+		if portPattern.MatchString(entry) || aliasNamePattern.MatchString(entry) {
+			return ""
+		}
+		return "expected a port number, a port range (e.g. 8000:8080) or an alias name"
+	case "url", "urltable", "urljson":
+		if u, err := url.Parse(entry); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+			return ""
+		}
+		return "expected an http(s) URL"
+	case "geoip":
+		if countryCodePattern.MatchString(entry) {
+			return ""
+		}
+		return "expected an upper-case ISO 3166 country code (e.g. NL)"
+	case "networkgroup":
+		if aliasNamePattern.MatchString(entry) {
+			return ""
+		}
+		return "expected the name of a network or host alias"
+	case "mac":
+		if macPattern.MatchString(entry) {
+			return ""
+		}
+		return "expected a MAC address or MAC prefix (e.g. 00:11:22)"
+	case "asn":
+		if asnPattern.MatchString(entry) {
+			return ""
+		}
+		return "expected an AS number"
+	case "dynipv6host":
+		// The interface prefix is combined with this host part
+		if ip := net.ParseIP(entry); ip != nil && ip.To4() == nil {
+			return ""
+		}
+		return "expected the IPv6 host part (e.g. ::1:2:3:4)"
+	case "authgroup":
+		if groupNamePattern.MatchString(entry) {
+			return ""
+		}
+		return "expected an OpenVPN group name"
+	case "external":
+		return "external aliases are filled outside of the configuration, content must be empty"
+	}
+	return ""
+}
+
+// isAliasAddress reports whether v is an IP address or an address range.
+func isAliasAddress(v string) bool {
+	if net.ParseIP(v) != nil {
+		return true
+	}
+	if from, to, ok := strings.Cut(v, "-"); ok {
+		return net.ParseIP(from) != nil && net.ParseIP(to) != nil
+	}
+	return false
+}
+
+// isAliasNetwork reports whether v is a CIDR network, an address or a range.
+func isAliasNetwork(v string) bool {
+	if _, _, err := net.ParseCIDR(v); err == nil {
+		return true
+	}
+	return isAliasAddress(v)
+}
+
+// aliasFieldMismatch returns an error message when attribute is set on an alias
+// type it doesn't apply to.
+func aliasFieldMismatch(attribute, aliasType string) string {
+	applies := aliasTypeFields[attribute]
+	if containsString(applies, aliasType) {
+		return ""
+	}
+	return fmt.Sprintf("`%s` only applies to alias types: %s", attribute, strings.Join(applies, ", "))
+}
+
+// normalizeAliasEntry returns the canonical form of a content entry, so
+// equivalent spellings ("10.0.0.1/32" and "10.0.0.1", upper and lower case
+// IPv6 or MAC addresses) compare equal.
+func normalizeAliasEntry(aliasType, entry string) string {
+	entry = strings.TrimSpace(entry)
+
+	switch aliasType {
+	case "host", "network":
+		negate := strings.HasPrefix(entry, "!")
+		v := strings.TrimPrefix(entry, "!")
+		if ip, ipNet, err := net.ParseCIDR(v); err == nil {
+			ones, bits := ipNet.Mask.Size()
+			if ones == bits {
+				// Single address networks are plain addresses
+				v = ip.String()
+			} else {
+				v = fmt.Sprintf("%s/%d", ip.String(), ones)
+			}
+		} else if ip := net.ParseIP(v); ip != nil {
+			v = ip.String()
+		}
+		if negate {
+			return "!" + v
+		}
+		return v
+	case "mac":
+		return strings.ToLower(strings.ReplaceAll(entry, "-", ":"))
+	case "geoip":
+		return strings.ToUpper(entry)
+	case "asn":
+		return strings.TrimPrefix(strings.ToUpper(entry), "AS")
+	case "dynipv6host":
+		if ip := net.ParseIP(entry); ip != nil {
+			return ip.String()
+		}
+	}
+	return entry
+}
+
+// keepConfiguredSpelling replaces API entries equal to a configured entry after
+// normalization by the configured spelling, so they don't show as changes.
+func keepConfiguredSpelling(aliasType string, configured, entries []string) []string {
+	spelling := make(map[string]string, len(configured))
+	for _, entry := range configured {
+		spelling[normalizeAliasEntry(aliasType, entry)] = entry
+	}
+
+	result := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if s, ok := spelling[normalizeAliasEntry(aliasType, entry)]; ok {
+			entry = s
+		}
+		result = append(result, entry)
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package provider
+
+import "testing"
+
+func TestValidateAliasEntry(t *testing.T) {
+	tests := []struct {
+		aliasType string
+		entry     string
+		valid     bool
+	}{
+		{"host", "10.0.0.1", true},
+		{"host", "!10.0.0.1", true},
+		{"host", "2001:db8::1", true},
+		{"host", "10.0.0.1-10.0.0.9", true},
+		{"host", "www.example.com", true},
+		{"host", "web_servers", true},
+		{"host", "10.0.0.0/24", false},
+		{"host", "bad host", false},
+		{"network", "10.0.0.0/24", true},
+		{"network", "!10.0.0.0/24", true},
+		{"network", "10.0.0.1", true},
+		{"network", "lan_networks", true},
+		// Interface networks are internal aliases, referenced by name
+		{"network", "__lan_network", true},
+		{"networkgroup", "__opt1_network", true},
+		{"network", "10.0.0.0/33", false},
+		{"port", "443", true},
+		{"port", "8000:8080", true},
+		{"port", "8000-8080", true},
+		{"port", "web_ports", true},
+		{"port", "443-", false},
+		{"url", "https://example.com/list.txt", true},
+		{"url", "ftp://example.com/list.txt", false},
+		{"geoip", "NL", true},
+		{"geoip", "nl", false},
+		{"mac", "aa:bb:cc:dd:ee:ff", true},
+		{"mac", "AA-BB-CC", true},
+		{"mac", "aa:bb:cc:dd:ee:ff:00", false},
+		{"asn", "AS13335", true},
+		{"asn", "13335", true},
+		{"asn", "ASX", false},
+		{"dynipv6host", "::1:2:3:4", true},
+		{"dynipv6host", "10.0.0.1", false},
+		{"authgroup", "vpn-users", true},
+		{"external", "10.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		msg := validateAliasEntry(tt.aliasType, tt.entry)
+		if (msg == "") != tt.valid {
+			t.Errorf("validateAliasEntry(%q, %q) = %q, want valid=%t", tt.aliasType, tt.entry, msg, tt.valid)
+		}
+	}
+}
+
+func TestAliasTypes(t *testing.T) {
+	// OPNsense maintains these itself, they can't be created through the API
+	for _, aliasType := range []string{"internal", "interface", "interface net"} {
+		if containsString(aliasTypes, aliasType) {
+			t.Errorf("aliasTypes contains %q", aliasType)
+		}
+	}
+}
+
+func TestNormalizeAliasEntry(t *testing.T) {
+	tests := []struct {
+		aliasType string
+		entry     string
+		want      string
+	}{
+		{"host", " 10.0.0.1 ", "10.0.0.1"},
+		{"host", "10.0.0.1/32", "10.0.0.1"},
+		{"host", "2001:DB8::1", "2001:db8::1"},
+		{"host", "2001:db8::1/128", "2001:db8::1"},
+		{"host", "!10.0.0.1/32", "!10.0.0.1"},
+		{"host", "web_servers", "web_servers"},
+		{"network", "10.0.0.0/24", "10.0.0.0/24"},
+		{"network", "2001:DB8::/64", "2001:db8::/64"},
+		{"mac", "AA-BB-CC-DD-EE-FF", "aa:bb:cc:dd:ee:ff"},
+		{"geoip", "nl", "NL"},
+		{"asn", "as13335", "13335"},
+		{"dynipv6host", "::0:1", "::1"},
+		{"port", "443", "443"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeAliasEntry(tt.aliasType, tt.entry); got != tt.want {
+			t.Errorf("normalizeAliasEntry(%q, %q) = %q, want %q", tt.aliasType, tt.entry, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &FirewallAliasTableDataSource{}
+
+func NewFirewallAliasTableDataSource() datasource.DataSource {
+	return &FirewallAliasTableDataSource{}
+}
+
+// FirewallAliasTableDataSource reads what pf has loaded for an alias, which
+// differs from the configured content for urltable, geoip and dynamic aliases.
+type FirewallAliasTableDataSource struct {
+	client *Client
+}
+
+type FirewallAliasTableDataSourceModel struct {
+	ID        types.String `tfsdk:"id"`
+	Alias     types.String `tfsdk:"alias"`
+	Addresses types.List   `tfsdk:"addresses"`
+	Count     types.Int64  `tfsdk:"count"`
+}
+
+func (d *FirewallAliasTableDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_firewall_alias_table"
+}
+
+func (d *FirewallAliasTableDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Fetches the addresses currently loaded in the pf table of an OPNsense alias",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Alias name",
+				Computed:            true,
+			},
+			"alias": schema.StringAttribute{
+				MarkdownDescription: "Name of the alias",
+				Required:            true,
+			},
+			"addresses": schema.ListAttribute{
+				MarkdownDescription: "Resolved addresses and networks, sorted",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"count": schema.Int64Attribute{
+				MarkdownDescription: "Number of entries in the table",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *FirewallAliasTableDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *FirewallAliasTableDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FirewallAliasTableDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	addresses, err := d.client.aliasTable(ctx, data.Alias.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list alias %s: %s", data.Alias.ValueString(), err))
+		return
+	}
+	sort.Strings(addresses)
+
+	list, diags := types.ListValueFrom(ctx, types.StringType, addresses)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = data.Alias
+	data.Addresses = list
+	data.Count = types.Int64Value(int64(len(addresses)))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
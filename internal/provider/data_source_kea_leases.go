@@ -0,0 +1,288 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &KeaLeasesDataSource{}
+
+func NewKeaLeasesDataSource() datasource.DataSource {
+	return &KeaLeasesDataSource{}
+}
+
+// KeaLeasesDataSource lists the current Kea leases, e.g. to find new devices
+// or to turn dynamic leases into reservations.
+type KeaLeasesDataSource struct {
+	client *Client
+}
+
+type KeaLeasesDataSourceModel struct {
+	ID        types.String `tfsdk:"id"`
+	IPVersion types.Int64  `tfsdk:"ip_version"`
+	Subnet    types.String `tfsdk:"subnet"`
+	HWAddress types.String `tfsdk:"hw_address"`
+	Hostname  types.String `tfsdk:"hostname"`
+	State     types.String `tfsdk:"state"`
+	Leases    types.List   `tfsdk:"leases"`
+}
+
+type keaLeaseModel struct {
+	Address   types.String `tfsdk:"address"`
+	HWAddress types.String `tfsdk:"hw_address"`
+	DUID      types.String `tfsdk:"duid"`
+	Hostname  types.String `tfsdk:"hostname"`
+	Expires   types.String `tfsdk:"expires"`
+	State     types.String `tfsdk:"state"`
+	Interface types.String `tfsdk:"interface"`
+}
+
+var keaLeaseAttrTypes = map[string]attr.Type{
+	"address":    types.StringType,
+	"hw_address": types.StringType,
+	"duid":       types.StringType,
+	"hostname":   types.StringType,
+	"expires":    types.StringType,
+	"state":      types.StringType,
+	"interface":  types.StringType,
+}
+
+// keaLeaseStates maps Kea's numeric lease states to the names used here.
+var keaLeaseStates = map[string]string{
+	"0": "active",
+	"1": "declined",
+	"2": "expired",
+}
+
+func (d *KeaLeasesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kea_leases"
+}
+
+func (d *KeaLeasesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Fetches the current Kea DHCP leases, optionally filtered by subnet, MAC address, hostname and state",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "`leases4` or `leases6`",
+				Computed:            true,
+			},
+			"ip_version": schema.Int64Attribute{
+				MarkdownDescription: "`4` (default) for DHCPv4 leases, `6` for DHCPv6 leases",
+				Optional:            true,
+			},
+			"subnet": schema.StringAttribute{
+				MarkdownDescription: "Only leases with an address inside this network (CIDR)",
+				Optional:            true,
+			},
+			"hw_address": schema.StringAttribute{
+				MarkdownDescription: "Only leases of this MAC address (any case, `:` or `-` separated)",
+				Optional:            true,
+			},
+			"hostname": schema.StringAttribute{
+				MarkdownDescription: "Only leases with this hostname (case-insensitive)",
+				Optional:            true,
+			},
+			"state": schema.StringAttribute{
+				MarkdownDescription: "Only leases in this state: `active`, `declined` or `expired`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringOneOf("active", "declined", "expired"),
+				},
+			},
+			"leases": schema.ListNestedAttribute{
+				MarkdownDescription: "Matching leases, sorted by address",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"address": schema.StringAttribute{
+							MarkdownDescription: "Leased address",
+							Computed:            true,
+						},
+						"hw_address": schema.StringAttribute{
+							MarkdownDescription: "MAC address of the client, lower case",
+							Computed:            true,
+						},
+						"duid": schema.StringAttribute{
+							MarkdownDescription: "DUID of the client (DHCPv6 leases)",
+							Computed:            true,
+						},
+						"hostname": schema.StringAttribute{
+							MarkdownDescription: "Hostname sent by the client",
+							Computed:            true,
+						},
+						"expires": schema.StringAttribute{
+							MarkdownDescription: "Expiry time (RFC 3339, UTC)",
+							Computed:            true,
+						},
+						"state": schema.StringAttribute{
+							MarkdownDescription: "Lease state: `active`, `declined` or `expired`",
+							Computed:            true,
+						},
+						"interface": schema.StringAttribute{
+							MarkdownDescription: "Interface the lease was handed out on",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *KeaLeasesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *KeaLeasesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data KeaLeasesDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	version := int64(4)
+	if !data.IPVersion.IsNull() {
+		version = data.IPVersion.ValueInt64()
+	}
+	if version != 4 && version != 6 {
+		resp.Diagnostics.AddAttributeError(path.Root("ip_version"), "Invalid IP Version", "ip_version must be 4 or 6.")
+		return
+	}
+
+	var subnet netip.Prefix
+	if !data.Subnet.IsNull() {
+		var err error
+		if subnet, err = netip.ParsePrefix(data.Subnet.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("subnet"), "Invalid Subnet",
+				fmt.Sprintf("%q is not a network in CIDR notation.", data.Subnet.ValueString()))
+			return
+		}
+	}
+
+	leases, err := d.client.keaLeases(ctx, version)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list Kea leases: %s", err))
+		return
+	}
+
+	models := []keaLeaseModel{}
+	for _, lease := range leases {
+		if subnet.IsValid() && !subnet.Contains(lease.address) {
+			continue
+		}
+		if !data.HWAddress.IsNull() && lease.hwAddress != normalizeMAC(data.HWAddress.ValueString()) {
+			continue
+		}
+		if !data.Hostname.IsNull() && !strings.EqualFold(lease.hostname, data.Hostname.ValueString()) {
+			continue
+		}
+		if !data.State.IsNull() && lease.state != data.State.ValueString() {
+			continue
+		}
+
+		expires := ""
+		if !lease.expires.IsZero() {
+			expires = lease.expires.UTC().Format(time.RFC3339)
+		}
+		models = append(models, keaLeaseModel{
+			Address:   types.StringValue(lease.address.String()),
+			HWAddress: types.StringValue(lease.hwAddress),
+			DUID:      types.StringValue(lease.duid),
+			Hostname:  types.StringValue(lease.hostname),
+			Expires:   types.StringValue(expires),
+			State:     types.StringValue(lease.state),
+			Interface: types.StringValue(lease.iface),
+		})
+	}
+
+	list, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: keaLeaseAttrTypes}, models)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("leases%d", version))
+	data.Leases = list
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// keaLease is a lease as reported by the leases search endpoints.
+type keaLease struct {
+	address   netip.Addr
+	hwAddress string
+	duid      string
+	hostname  string
+	expires   time.Time
+	state     string
+	iface     string
+}
+
+// keaLeases returns the current leases of the DHCPv4 or DHCPv6 server, sorted
+// by address. Rows without a parseable address are skipped.
+func (c *Client) keaLeases(ctx context.Context, version int64) ([]keaLease, error) {
+	rows, err := c.searchItems(ctx, fmt.Sprintf("kea/leases%d/search", version))
+	if err != nil {
+		return nil, err
+	}
+
+	leases := make([]keaLease, 0, len(rows))
+	for _, row := range rows {
+		address, err := netip.ParseAddr(stringField(row, "address"))
+		if err != nil {
+			continue
+		}
+
+		lease := keaLease{
+			address:   address,
+			hwAddress: normalizeMAC(stringField(row, "hwaddr")),
+			duid:      strings.ToLower(stringField(row, "duid")),
+			hostname:  strings.TrimSuffix(stringField(row, "hostname"), "."),
+			state:     keaLeaseStates[stringField(row, "state")],
+			iface:     stringField(row, "if_name"),
+		}
+		if expire, ok := int64Field(row, "expire"); ok && expire > 0 {
+			lease.expires = time.Unix(expire, 0)
+		}
+		if lease.state == "" {
+			lease.state = stringField(row, "state")
+		}
+		leases = append(leases, lease)
+	}
+
+	sort.Slice(leases, func(i, j int) bool {
+		return leases[i].address.Less(leases[j].address)
+	})
+	return leases, nil
+}
+
+// normalizeMAC returns a MAC address in lower case with colons, the form
+// Kea reports hardware addresses in.
+func normalizeMAC(v string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(v), "-", ":"))
+}
@@ -0,0 +1,271 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// boolString converts a bool to the "1"/"0" strings OPNsense models expect.
+func boolString(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// stringField returns m[key] as a string, whatever JSON type it was decoded as.
+func stringField(m map[string]interface{}, key string) string {
+	switch v := m[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return boolString(v)
+	default:
+		return ""
+	}
+}
+
+// boolField returns true when m[key] holds OPNsense's "1".
+func boolField(m map[string]interface{}, key string) bool {
+	return stringField(m, key) == "1"
+}
+
+// selectedOptions returns the selected keys of an option field. getItem style
+// endpoints render option lists as {"key": {"value": "...", "selected": 1}},
+// while plain strings (comma-separated for multi-selects) are returned as-is.
+func selectedOptions(v interface{}) []string {
+	var selected []string
+
+	switch opts := v.(type) {
+	case string:
+		for _, item := range strings.Split(opts, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				selected = append(selected, item)
+			}
+		}
+	case map[string]interface{}:
+		for key, raw := range opts {
+			opt, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			switch s := opt["selected"].(type) {
+			case float64:
+				if s == 1 {
+					selected = append(selected, key)
+				}
+			case bool:
+				if s {
+					selected = append(selected, key)
+				}
+			case string:
+				if s == "1" {
+					selected = append(selected, key)
+				}
+			}
+		}
+		// Map iteration order is random, keep results deterministic
+		sort.Strings(selected)
+	}
+
+	return selected
+}
+
+// selectedOption returns the single selected key of an option field.
+func selectedOption(v interface{}) string {
+	selected := selectedOptions(v)
+	if len(selected) == 0 {
+		return ""
+	}
+	return selected[0]
+}
+
+// int64Field parses m[key] as an integer, returning ok=false for empty values.
+func int64Field(m map[string]interface{}, key string) (int64, bool) {
+	s := stringField(m, key)
+	if s == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// int64String formats n the way OPNsense integer fields are posted.
+func int64String(n int64) string {
+	return fmt.Sprintf("%d", n)
+}
+
+// containsString reports whether s is present in list.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// stringFromAPI maps an API string onto an optional attribute. Empty values
+// stay null when the attribute was never set, so defaults don't show as drift.
+func stringFromAPI(current types.String, v string) types.String {
+	if v == "" && current.IsNull() {
+		return types.StringNull()
+	}
+	return types.StringValue(v)
+}
+
+// boolFromAPI maps an API "0"/"1" onto an optional attribute, keeping an unset
+// attribute null while OPNsense reports the default false.
+func boolFromAPI(current types.Bool, v bool) types.Bool {
+	if !v && current.IsNull() {
+		return types.BoolNull()
+	}
+	return types.BoolValue(v)
+}
+
+// int64FromAPI maps an API integer field onto an optional attribute.
+func int64FromAPI(current types.Int64, m map[string]interface{}, key string) types.Int64 {
+	n, ok := int64Field(m, key)
+	if !ok {
+		if current.IsNull() {
+			return types.Int64Null()
+		}
+		return types.Int64Value(0)
+	}
+	return types.Int64Value(n)
+}
+
+// setFromAPI maps a list of API values onto an optional set of strings.
+func setFromAPI(ctx context.Context, current types.Set, values []string) (types.Set, diag.Diagnostics) {
+	if len(values) == 0 && current.IsNull() {
+		return types.SetNull(types.StringType), nil
+	}
+	if values == nil {
+		values = []string{}
+	}
+	return types.SetValueFrom(ctx, types.StringType, values)
+}
+
+// stringOneOfValidator checks a string attribute against a fixed list of values.
+type stringOneOfValidator struct {
+	values []string
+}
+
+// stringOneOf returns a validator accepting only the given values.
+func stringOneOf(values ...string) validator.String {
+	return stringOneOfValidator{values: values}
+}
+
+func (v stringOneOfValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be one of: %s", strings.Join(v.values, ", "))
+}
+
+func (v stringOneOfValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v stringOneOfValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if containsString(v.values, req.ConfigValue.ValueString()) {
+		return
+	}
+	resp.Diagnostics.AddAttributeError(
+		req.Path,
+		"Invalid Attribute Value",
+		fmt.Sprintf("Got %q, %s", req.ConfigValue.ValueString(), v.Description(ctx)),
+	)
+}
+
+// joinSorted joins values comma-separated in a stable order.
+func joinSorted(values []string) string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// stringFromAPIDefault is stringFromAPI for fields OPNsense fills with a
+// default value, which is left null when the attribute was never set.
+func stringFromAPIDefault(current types.String, v, def string) types.String {
+	if v == def && current.IsNull() {
+		return types.StringNull()
+	}
+	return stringFromAPI(current, v)
+}
+
+// valueOrDefault returns the attribute value, or def when it is null or unknown.
+func valueOrDefault(v types.String, def string) string {
+	if v.IsNull() || v.IsUnknown() || v.ValueString() == "" {
+		return def
+	}
+	return v.ValueString()
+}
+
+// listFromAPI maps API values onto an optional list of strings, keeping an
+// unset attribute null while OPNsense reports no values.
+func listFromAPI(ctx context.Context, current types.List, values []string, diags *diag.Diagnostics) types.List {
+	if len(values) == 0 && current.IsNull() {
+		return types.ListNull(types.StringType)
+	}
+	if values == nil {
+		values = []string{}
+	}
+	list, d := types.ListValueFrom(ctx, types.StringType, values)
+	diags.Append(d...)
+	return list
+}
+
+// listValues returns the elements of a list of strings, nil when it is null
+// or unknown.
+func listValues(ctx context.Context, list types.List, diags *diag.Diagnostics) []string {
+	if list.IsNull() || list.IsUnknown() {
+		return nil
+	}
+	var values []string
+	diags.Append(list.ElementsAs(ctx, &values, false)...)
+	return values
+}
+
+// forEachKnownString calls fn for every known element of a list of strings,
+// used by plan-time validation where elements may still be unknown.
+func forEachKnownString(ctx context.Context, list types.List, diags *diag.Diagnostics, fn func(i int, v string)) {
+	if list.IsNull() || list.IsUnknown() {
+		return
+	}
+	var elems []types.String
+	diags.Append(list.ElementsAs(ctx, &elems, false)...)
+	for i, v := range elems {
+		if v.IsNull() || v.IsUnknown() {
+			continue
+		}
+		fn(i, v.ValueString())
+	}
+}
+
+// putKnownBool sets m[key] to the OPNsense form of v when v is known. Used by
+// settings resources that only post the values present in the configuration.
+func putKnownBool(m map[string]interface{}, key string, v types.Bool) {
+	if !v.IsNull() && !v.IsUnknown() {
+		m[key] = boolString(v.ValueBool())
+	}
+}
+
+// putKnownInt64 sets m[key] to the OPNsense form of v when v is known.
+func putKnownInt64(m map[string]interface{}, key string, v types.Int64) {
+	if !v.IsNull() && !v.IsUnknown() {
+		m[key] = int64String(v.ValueInt64())
+	}
+}
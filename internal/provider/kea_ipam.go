@@ -0,0 +1,175 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// keaAllocMu serializes address allocation with the reservation create, so
+// reservations created in parallel in one apply never pick the same address.
+var keaAllocMu sync.Mutex
+
+// keaSubnet4 resolves a DHCPv4 subnet reference, either its UUID or its CIDR,
+// to the subnet UUID and network. ok is false when no such subnet exists.
+func (c *Client) keaSubnet4(ctx context.Context, ref string) (id string, network netip.Prefix, ok bool, err error) {
+	if p, err := netip.ParsePrefix(strings.TrimSpace(ref)); err == nil {
+		rows, err := c.searchItems(ctx, "kea/dhcpv4/search_subnet")
+		if err != nil {
+			return "", netip.Prefix{}, false, err
+		}
+		for _, row := range rows {
+			if rp, err := netip.ParsePrefix(stringField(row, "subnet")); err == nil && rp.Masked() == p.Masked() {
+				return stringField(row, "uuid"), rp.Masked(), true, nil
+			}
+		}
+		return "", p.Masked(), false, nil
+	}
+
+	subnet, err := c.getItem(ctx, "kea/dhcpv4/get_subnet/"+ref, "subnet4")
+	if err != nil || subnet == nil {
+		return "", netip.Prefix{}, false, err
+	}
+	network, _ = netip.ParsePrefix(stringField(subnet, "subnet"))
+	return ref, network.Masked(), true, nil
+}
+
+// nextFreeKeaAddress returns the lowest address of a DHCPv4 subnet that is
+// outside its dynamic pools, not the network, broadcast, router or firewall
+// interface address, and not used by another reservation or a current lease.
+// Callers hold keaAllocMu until the reservation is saved.
+func (c *Client) nextFreeKeaAddress(ctx context.Context, subnetID string) (string, error) {
+	subnet, err := c.getItem(ctx, "kea/dhcpv4/get_subnet/"+subnetID, "subnet4")
+	if err != nil {
+		return "", err
+	}
+	if subnet == nil {
+		return "", fmt.Errorf("subnet %s not found", subnetID)
+	}
+
+	prefix, err := netip.ParsePrefix(stringField(subnet, "subnet"))
+	if err != nil || !prefix.Addr().Is4() {
+		return "", fmt.Errorf("subnet %s has no IPv4 network", subnetID)
+	}
+	prefix = prefix.Masked()
+
+	var pools []addrRange
+	for _, s := range splitKeaPools(stringField(subnet, "pools")) {
+		if pool, err := parseKeaPool(s); err == nil {
+			pools = append(pools, pool)
+		}
+	}
+
+	used := map[netip.Addr]bool{}
+	hasRouter := false
+	if options, ok := subnet["option_data"].(map[string]interface{}); ok {
+		for _, router := range strings.Split(stringField(options, "routers"), ",") {
+			if a, err := netip.ParseAddr(strings.TrimSpace(router)); err == nil {
+				used[a] = true
+				hasRouter = true
+			}
+		}
+	}
+
+	// With auto_collect the router is the interface address, which is not in
+	// option_data; skip every address the firewall holds, VIPs included
+	if addrs, err := c.interfaceAddresses(ctx); err == nil {
+		for _, a := range addrs {
+			used[a] = true
+		}
+	} else {
+		tflog.Warn(ctx, "Unable to read interface addresses", map[string]any{"error": err.Error()})
+		if !hasRouter && prefix.Bits() < 31 {
+			// The gateway conventionally is the first host
+			used[prefix.Addr().Next()] = true
+		}
+	}
+
+	rows, err := c.searchItems(ctx, "kea/dhcpv4/search_reservation")
+	if err != nil {
+		return "", fmt.Errorf("unable to list reservations: %w", err)
+	}
+	for _, row := range rows {
+		if a, err := netip.ParseAddr(stringField(row, "ip_address")); err == nil {
+			used[a] = true
+		}
+	}
+
+	leases, err := c.keaLeases(ctx, 4)
+	if err != nil {
+		return "", fmt.Errorf("unable to list leases: %w", err)
+	}
+	for _, lease := range leases {
+		if lease.state != "expired" {
+			used[lease.address] = true
+		}
+	}
+
+	a, ok := firstFreeAddress(prefix, pools, used)
+	if !ok {
+		return "", fmt.Errorf("no free address left in %s outside the dynamic pools", prefix)
+	}
+	return a.String(), nil
+}
+
+// firstFreeAddress returns the lowest host address of prefix that is not in
+// used and not inside one of pools. The network and broadcast address are
+// skipped except in /31 and /32 networks, which have none (RFC 3021).
+func firstFreeAddress(prefix netip.Prefix, pools []addrRange, used map[netip.Addr]bool) (netip.Addr, bool) {
+	hosts := prefixRange(prefix)
+	if prefix.Bits() < 31 {
+		hosts = addrRange{first: hosts.first.Next(), last: hosts.last.Prev()}
+	}
+
+next:
+	for a := hosts.first; a.IsValid() && !hosts.last.Less(a); a = a.Next() {
+		if used[a] {
+			continue
+		}
+		for _, pool := range pools {
+			if pool.contains(a) {
+				continue next
+			}
+		}
+		return a, true
+	}
+
+	return netip.Addr{}, false
+}
+
+// interfaceAddresses returns the IPv4 addresses configured on the firewall's
+// interfaces, including aliases and CARP VIPs.
+func (c *Client) interfaceAddresses(ctx context.Context) ([]netip.Addr, error) {
+	result, err := c.doJSON(ctx, "GET", "diagnostics/interface/getInterfaceConfig", nil)
+	if err != nil {
+		return nil, err
+	}
+	return interfaceAddressesFromAPI(result), nil
+}
+
+// interfaceAddressesFromAPI collects the ipv4 addresses of getInterfaceConfig,
+// which is keyed by device name.
+func interfaceAddressesFromAPI(result map[string]interface{}) []netip.Addr {
+	var addrs []netip.Addr
+	for _, raw := range result {
+		device, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		entries, _ := device["ipv4"].([]interface{})
+		for _, entry := range entries {
+			e, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if a, err := netip.ParseAddr(stringField(e, "ipaddr")); err == nil {
+				addrs = append(addrs, a)
+			}
+		}
+	}
+	return addrs
+}
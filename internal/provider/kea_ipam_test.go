@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/netip"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestFirstFreeAddress(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		pools  []string
+		used   []string
+		want   string // empty when the subnet is full
+	}{
+		{
+			name:   "first host",
+			prefix: "10.0.10.0/24",
+			want:   "10.0.10.1",
+		},
+		{
+			name:   "router taken",
+			prefix: "10.0.10.0/24",
+			used:   []string{"10.0.10.1"},
+			want:   "10.0.10.2",
+		},
+		{
+			name:   "pool at the start",
+			prefix: "10.0.10.0/24",
+			pools:  []string{"10.0.10.1-10.0.10.99"},
+			used:   []string{"10.0.10.100"},
+			want:   "10.0.10.101",
+		},
+		{
+			name:   "gap between pools",
+			prefix: "10.0.10.0/24",
+			pools:  []string{"10.0.10.1-10.0.10.99", "10.0.10.101-10.0.10.254"},
+			want:   "10.0.10.100",
+		},
+		{
+			name:   "cidr pool",
+			prefix: "10.0.10.0/24",
+			pools:  []string{"10.0.10.0/25"},
+			want:   "10.0.10.128",
+		},
+		{
+			name:   "broadcast is never handed out",
+			prefix: "10.0.10.0/24",
+			pools:  []string{"10.0.10.1-10.0.10.253"},
+			used:   []string{"10.0.10.254"},
+		},
+		{
+			name:   "last host",
+			prefix: "10.0.10.0/24",
+			pools:  []string{"10.0.10.1-10.0.10.253"},
+			want:   "10.0.10.254",
+		},
+		{
+			name:   "/30",
+			prefix: "10.0.10.4/30",
+			used:   []string{"10.0.10.5"},
+			want:   "10.0.10.6",
+		},
+		{
+			name:   "/30 full",
+			prefix: "10.0.10.4/30",
+			used:   []string{"10.0.10.5", "10.0.10.6"},
+		},
+		{
+			name:   "/31 uses both addresses",
+			prefix: "10.0.10.4/31",
+			want:   "10.0.10.4",
+		},
+		{
+			name:   "/31 second address",
+			prefix: "10.0.10.4/31",
+			used:   []string{"10.0.10.4"},
+			want:   "10.0.10.5",
+		},
+		{
+			name:   "/32",
+			prefix: "10.0.10.9/32",
+			want:   "10.0.10.9",
+		},
+		{
+			name:   "/32 taken",
+			prefix: "10.0.10.9/32",
+			used:   []string{"10.0.10.9"},
+		},
+		{
+			name:   "/32 inside a pool",
+			prefix: "10.0.10.9/32",
+			pools:  []string{"10.0.10.9/32"},
+		},
+		{
+			name:   "addresses outside the subnet are ignored",
+			prefix: "10.0.10.0/29",
+			used:   []string{"10.0.9.1", "10.0.10.1", "10.0.10.8"},
+			want:   "10.0.10.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pools []addrRange
+			for _, s := range tt.pools {
+				pool, err := parseKeaPool(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				pools = append(pools, pool)
+			}
+			used := map[netip.Addr]bool{}
+			for _, s := range tt.used {
+				used[netip.MustParseAddr(s)] = true
+			}
+
+			got, ok := firstFreeAddress(netip.MustParsePrefix(tt.prefix), pools, used)
+			switch {
+			case tt.want == "" && ok:
+				t.Errorf("firstFreeAddress() = %s, want none", got)
+			case tt.want != "" && (!ok || got.String() != tt.want):
+				t.Errorf("firstFreeAddress() = %s, %v, want %s", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterfaceAddressesFromAPI(t *testing.T) {
+	var result map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"em0": {"ipv4": [{"ipaddr": "10.0.10.1", "subnetbits": 24}, {"ipaddr": "10.0.10.254", "subnetbits": 32}],
+		        "ipv6": [{"ipaddr": "fe80::1", "subnetbits": 64}]},
+		"em1": {"ipv4": []},
+		"lo0": {"ipv4": [{"ipaddr": "127.0.0.1", "subnetbits": 8}]},
+		"pflog0": {"flags": ["up"]},
+		"bogus": "x"
+	}`), &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, a := range interfaceAddressesFromAPI(result) {
+		got = append(got, a.String())
+	}
+	sort.Strings(got)
+
+	want := []string{"10.0.10.1", "10.0.10.254", "127.0.0.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("interfaceAddressesFromAPI() = %q, want %q", got, want)
+	}
+}
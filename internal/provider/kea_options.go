@@ -0,0 +1,376 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// keaOptionDataModel is the typed form of the option_data container of Kea
+// DHCPv4 subnets.
+type keaOptionDataModel struct {
+	Routers           types.List   `tfsdk:"routers"`
+	DomainNameServers types.List   `tfsdk:"domain_name_servers"`
+	DomainName        types.String `tfsdk:"domain_name"`
+	DomainSearch      types.List   `tfsdk:"domain_search"`
+	NTPServers        types.List   `tfsdk:"ntp_servers"`
+	TimeServers       types.List   `tfsdk:"time_servers"`
+	TFTPServerName    types.String `tfsdk:"tftp_server_name"`
+	BootFileName      types.String `tfsdk:"boot_file_name"`
+	StaticRoutes      types.List   `tfsdk:"static_routes"`
+	V6OnlyPreferred   types.Int64  `tfsdk:"v6_only_preferred"`
+}
+
+// keaStaticRouteModel is a destination/router pair of the static-routes option.
+type keaStaticRouteModel struct {
+	Destination types.String `tfsdk:"destination"`
+	Router      types.String `tfsdk:"router"`
+}
+
+var keaStaticRouteAttrTypes = map[string]attr.Type{
+	"destination": types.StringType,
+	"router":      types.StringType,
+}
+
+var keaOptionDataAttrTypes = map[string]attr.Type{
+	"routers":             types.ListType{ElemType: types.StringType},
+	"domain_name_servers": types.ListType{ElemType: types.StringType},
+	"domain_name":         types.StringType,
+	"domain_search":       types.ListType{ElemType: types.StringType},
+	"ntp_servers":         types.ListType{ElemType: types.StringType},
+	"time_servers":        types.ListType{ElemType: types.StringType},
+	"tftp_server_name":    types.StringType,
+	"boot_file_name":      types.StringType,
+	"static_routes":       types.ListType{ElemType: types.ObjectType{AttrTypes: keaStaticRouteAttrTypes}},
+	"v6_only_preferred":   types.Int64Type,
+}
+
+// keaOptionKeys are the option_data fields of the OPNsense Kea model.
+var keaOptionKeys = []string{
+	"routers", "domain_name_servers", "domain_name", "domain_search", "ntp_servers",
+	"time_servers", "tftp_server_name", "boot_file_name", "static_routes", "v6_only_preferred",
+}
+
+// keaOptionDataSchema returns the option_data attribute shared by subnets and
+// reservations.
+func keaOptionDataSchema(description string) schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: description,
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"routers": schema.ListAttribute{
+				MarkdownDescription: "Default gateways (option 3)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"domain_name_servers": schema.ListAttribute{
+				MarkdownDescription: "DNS servers in order of preference (option 6)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"domain_name": schema.StringAttribute{
+				MarkdownDescription: "DNS domain of the clients (option 15)",
+				Optional:            true,
+			},
+			"domain_search": schema.ListAttribute{
+				MarkdownDescription: "DNS search domains (option 119)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"ntp_servers": schema.ListAttribute{
+				MarkdownDescription: "NTP servers (option 42)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"time_servers": schema.ListAttribute{
+				MarkdownDescription: "RFC 868 time servers (option 4)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"tftp_server_name": schema.StringAttribute{
+				MarkdownDescription: "TFTP server name or address for network boot (option 66)",
+				Optional:            true,
+			},
+			"boot_file_name": schema.StringAttribute{
+				MarkdownDescription: "Boot file name for network boot (option 67, e.g. 'pxelinux.0')",
+				Optional:            true,
+			},
+			"static_routes": schema.ListNestedAttribute{
+				MarkdownDescription: "Classful static routes (option 33)",
+				Optional:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"destination": schema.StringAttribute{
+							MarkdownDescription: "Destination host address",
+							Required:            true,
+						},
+						"router": schema.StringAttribute{
+							MarkdownDescription: "Router to reach the destination through",
+							Required:            true,
+						},
+					},
+				},
+			},
+			"v6_only_preferred": schema.Int64Attribute{
+				MarkdownDescription: "Seconds IPv6-only capable clients should disable IPv4 for (option 108, RFC 8925)",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// validateKeaOptions checks the addresses and names of an option_data object.
+// Unknown values are skipped, they are checked again at apply time.
+func validateKeaOptions(ctx context.Context, obj types.Object, p path.Path, diags *diag.Diagnostics) {
+	if obj.IsNull() || obj.IsUnknown() {
+		return
+	}
+
+	var opts keaOptionDataModel
+	diags.Append(obj.As(ctx, &opts, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return
+	}
+
+	for name, list := range map[string]types.List{
+		"routers":             opts.Routers,
+		"domain_name_servers": opts.DomainNameServers,
+		"ntp_servers":         opts.NTPServers,
+		"time_servers":        opts.TimeServers,
+	} {
+		forEachKnownString(ctx, list, diags, func(i int, v string) {
+			if ip := net.ParseIP(v); ip == nil || ip.To4() == nil {
+				diags.AddAttributeError(p.AtName(name).AtListIndex(i), "Invalid Address",
+					fmt.Sprintf("%q is not an IPv4 address.", v))
+			}
+		})
+	}
+
+	forEachKnownString(ctx, opts.DomainSearch, diags, func(i int, v string) {
+		if !hostnamePattern.MatchString(v) {
+			diags.AddAttributeError(p.AtName("domain_search").AtListIndex(i), "Invalid Domain",
+				fmt.Sprintf("%q is not a valid domain name.", v))
+		}
+	})
+	if v := opts.DomainName; !v.IsNull() && !v.IsUnknown() && !hostnamePattern.MatchString(v.ValueString()) {
+		diags.AddAttributeError(p.AtName("domain_name"), "Invalid Domain",
+			fmt.Sprintf("%q is not a valid domain name.", v.ValueString()))
+	}
+
+	if !opts.StaticRoutes.IsNull() && !opts.StaticRoutes.IsUnknown() {
+		var routes []keaStaticRouteModel
+		diags.Append(opts.StaticRoutes.ElementsAs(ctx, &routes, false)...)
+		for i, route := range routes {
+			for name, v := range map[string]types.String{"destination": route.Destination, "router": route.Router} {
+				if v.IsNull() || v.IsUnknown() {
+					continue
+				}
+				if ip := net.ParseIP(v.ValueString()); ip == nil || ip.To4() == nil {
+					diags.AddAttributeError(p.AtName("static_routes").AtListIndex(i).AtName(name), "Invalid Address",
+						fmt.Sprintf("%q is not an IPv4 address.", v.ValueString()))
+				}
+			}
+		}
+	}
+
+	if v := opts.V6OnlyPreferred; !v.IsNull() && !v.IsUnknown() && (v.ValueInt64() < 0 || v.ValueInt64() > 4294967295) {
+		diags.AddAttributeError(p.AtName("v6_only_preferred"), "Invalid Attribute Value",
+			"v6_only_preferred must be between 0 and 4294967295 seconds.")
+	}
+}
+
+// keaOptionsPayload renders option_data the way the OPNsense model stores it:
+// flat strings, list options comma-separated. Options missing from obj are
+// sent empty so removing them from the configuration clears them.
+func keaOptionsPayload(ctx context.Context, obj types.Object, diags *diag.Diagnostics) map[string]interface{} {
+	var opts keaOptionDataModel
+	if !obj.IsNull() && !obj.IsUnknown() {
+		diags.Append(obj.As(ctx, &opts, basetypes.ObjectAsOptions{})...)
+	}
+
+	var routes []string
+	if !opts.StaticRoutes.IsNull() && !opts.StaticRoutes.IsUnknown() {
+		var models []keaStaticRouteModel
+		diags.Append(opts.StaticRoutes.ElementsAs(ctx, &models, false)...)
+		for _, route := range models {
+			routes = append(routes, route.Destination.ValueString(), route.Router.ValueString())
+		}
+	}
+
+	payload := map[string]interface{}{
+		"routers":             strings.Join(listValues(ctx, opts.Routers, diags), ","),
+		"domain_name_servers": strings.Join(listValues(ctx, opts.DomainNameServers, diags), ","),
+		"domain_name":         opts.DomainName.ValueString(),
+		"domain_search":       strings.Join(listValues(ctx, opts.DomainSearch, diags), ","),
+		"ntp_servers":         strings.Join(listValues(ctx, opts.NTPServers, diags), ","),
+		"time_servers":        strings.Join(listValues(ctx, opts.TimeServers, diags), ","),
+		"tftp_server_name":    opts.TFTPServerName.ValueString(),
+		"boot_file_name":      opts.BootFileName.ValueString(),
+		"static_routes":       strings.Join(routes, ","),
+		"v6_only_preferred":   "",
+	}
+	if !opts.V6OnlyPreferred.IsNull() && !opts.V6OnlyPreferred.IsUnknown() {
+		payload["v6_only_preferred"] = int64String(opts.V6OnlyPreferred.ValueInt64())
+	}
+	return payload
+}
+
+// keaOptionsFromAPI maps the option_data returned by a get endpoint onto the
+// option_data attribute. Unset options stay null, and the whole object stays
+// null when it was never configured and OPNsense has no options either.
+func keaOptionsFromAPI(ctx context.Context, current types.Object, raw interface{}, diags *diag.Diagnostics) types.Object {
+	opts, _ := raw.(map[string]interface{})
+
+	empty := true
+	for _, key := range keaOptionKeys {
+		if len(selectedOptions(opts[key])) > 0 {
+			empty = false
+			break
+		}
+	}
+	if empty && current.IsNull() {
+		return types.ObjectNull(keaOptionDataAttrTypes)
+	}
+
+	var cur keaOptionDataModel
+	if !current.IsNull() && !current.IsUnknown() {
+		diags.Append(current.As(ctx, &cur, basetypes.ObjectAsOptions{})...)
+	}
+
+	model := keaOptionDataModel{
+		Routers:           listFromAPI(ctx, cur.Routers, selectedOptions(opts["routers"]), diags),
+		DomainNameServers: listFromAPI(ctx, cur.DomainNameServers, selectedOptions(opts["domain_name_servers"]), diags),
+		DomainName:        stringFromAPI(cur.DomainName, stringField(opts, "domain_name")),
+		DomainSearch:      listFromAPI(ctx, cur.DomainSearch, selectedOptions(opts["domain_search"]), diags),
+		NTPServers:        listFromAPI(ctx, cur.NTPServers, selectedOptions(opts["ntp_servers"]), diags),
+		TimeServers:       listFromAPI(ctx, cur.TimeServers, selectedOptions(opts["time_servers"]), diags),
+		TFTPServerName:    stringFromAPI(cur.TFTPServerName, stringField(opts, "tftp_server_name")),
+		BootFileName:      stringFromAPI(cur.BootFileName, stringField(opts, "boot_file_name")),
+		V6OnlyPreferred:   int64FromAPI(cur.V6OnlyPreferred, opts, "v6_only_preferred"),
+	}
+
+	// static_routes is a flat list of destination,router pairs
+	routeType := types.ObjectType{AttrTypes: keaStaticRouteAttrTypes}
+	values := selectedOptions(opts["static_routes"])
+	if len(values) == 0 && cur.StaticRoutes.IsNull() {
+		model.StaticRoutes = types.ListNull(routeType)
+	} else {
+		routes := make([]keaStaticRouteModel, 0, len(values)/2)
+		for i := 0; i+1 < len(values); i += 2 {
+			routes = append(routes, keaStaticRouteModel{
+				Destination: types.StringValue(values[i]),
+				Router:      types.StringValue(values[i+1]),
+			})
+		}
+		list, d := types.ListValueFrom(ctx, routeType, routes)
+		diags.Append(d...)
+		model.StaticRoutes = list
+	}
+
+	obj, d := types.ObjectValueFrom(ctx, keaOptionDataAttrTypes, model)
+	diags.Append(d...)
+	return obj
+}
+
+// keaOption6DataModel is the option_data container of Kea DHCPv6 subnets.
+type keaOption6DataModel struct {
+	DNSServers   types.List `tfsdk:"dns_servers"`
+	DomainSearch types.List `tfsdk:"domain_search"`
+}
+
+var keaOption6DataAttrTypes = map[string]attr.Type{
+	"dns_servers":   types.ListType{ElemType: types.StringType},
+	"domain_search": types.ListType{ElemType: types.StringType},
+}
+
+// keaOption6DataSchema returns the option_data attribute of DHCPv6 subnets.
+func keaOption6DataSchema(description string) schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		MarkdownDescription: description,
+		Optional:            true,
+		Attributes: map[string]schema.Attribute{
+			"dns_servers": schema.ListAttribute{
+				MarkdownDescription: "IPv6 DNS servers in order of preference (option 23)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"domain_search": schema.ListAttribute{
+				MarkdownDescription: "DNS search domains (option 24)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+		},
+	}
+}
+
+// validateKeaOptions6 checks the addresses and names of a DHCPv6 option_data object.
+func validateKeaOptions6(ctx context.Context, obj types.Object, p path.Path, diags *diag.Diagnostics) {
+	if obj.IsNull() || obj.IsUnknown() {
+		return
+	}
+
+	var opts keaOption6DataModel
+	diags.Append(obj.As(ctx, &opts, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return
+	}
+
+	forEachKnownString(ctx, opts.DNSServers, diags, func(i int, v string) {
+		if ip := net.ParseIP(v); ip == nil || ip.To4() != nil {
+			diags.AddAttributeError(p.AtName("dns_servers").AtListIndex(i), "Invalid Address",
+				fmt.Sprintf("%q is not an IPv6 address.", v))
+		}
+	})
+	forEachKnownString(ctx, opts.DomainSearch, diags, func(i int, v string) {
+		if !hostnamePattern.MatchString(v) {
+			diags.AddAttributeError(p.AtName("domain_search").AtListIndex(i), "Invalid Domain",
+				fmt.Sprintf("%q is not a valid domain name.", v))
+		}
+	})
+}
+
+// keaOptions6Payload renders DHCPv6 option_data, see keaOptionsPayload.
+func keaOptions6Payload(ctx context.Context, obj types.Object, diags *diag.Diagnostics) map[string]interface{} {
+	var opts keaOption6DataModel
+	if !obj.IsNull() && !obj.IsUnknown() {
+		diags.Append(obj.As(ctx, &opts, basetypes.ObjectAsOptions{})...)
+	}
+
+	return map[string]interface{}{
+		"dns_servers":   strings.Join(listValues(ctx, opts.DNSServers, diags), ","),
+		"domain_search": strings.Join(listValues(ctx, opts.DomainSearch, diags), ","),
+	}
+}
+
+// keaOptions6FromAPI maps DHCPv6 option_data back, see keaOptionsFromAPI.
+func keaOptions6FromAPI(ctx context.Context, current types.Object, raw interface{}, diags *diag.Diagnostics) types.Object {
+	opts, _ := raw.(map[string]interface{})
+
+	servers := selectedOptions(opts["dns_servers"])
+	search := selectedOptions(opts["domain_search"])
+	if len(servers) == 0 && len(search) == 0 && current.IsNull() {
+		return types.ObjectNull(keaOption6DataAttrTypes)
+	}
+
+	var cur keaOption6DataModel
+	if !current.IsNull() && !current.IsUnknown() {
+		diags.Append(current.As(ctx, &cur, basetypes.ObjectAsOptions{})...)
+	}
+
+	model := keaOption6DataModel{
+		DNSServers:   listFromAPI(ctx, cur.DNSServers, servers, diags),
+		DomainSearch: listFromAPI(ctx, cur.DomainSearch, search, diags),
+	}
+
+	obj, d := types.ObjectValueFrom(ctx, keaOption6DataAttrTypes, model)
+	diags.Append(d...)
+	return obj
+}
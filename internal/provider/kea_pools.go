@@ -0,0 +1,298 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// keaPoolModel is a dynamic address pool, either a start/end range or a CIDR.
+type keaPoolModel struct {
+	Start types.String `tfsdk:"start"`
+	End   types.String `tfsdk:"end"`
+	CIDR  types.String `tfsdk:"cidr"`
+}
+
+var keaPoolAttrTypes = map[string]attr.Type{
+	"start": types.StringType,
+	"end":   types.StringType,
+	"cidr":  types.StringType,
+}
+
+// addrRange is an inclusive range of addresses of one family.
+type addrRange struct {
+	first netip.Addr
+	last  netip.Addr
+}
+
+func (r addrRange) contains(a netip.Addr) bool {
+	return r.first.Compare(a) <= 0 && a.Compare(r.last) <= 0
+}
+
+func (r addrRange) overlaps(o addrRange) bool {
+	return r.first.Compare(o.last) <= 0 && o.first.Compare(r.last) <= 0
+}
+
+func (r addrRange) String() string {
+	return r.first.String() + "-" + r.last.String()
+}
+
+// keaPoolsSchema returns the pools attribute shared by v4 and v6 subnets.
+func keaPoolsSchema(description string) schema.ListNestedAttribute {
+	return schema.ListNestedAttribute{
+		MarkdownDescription: description,
+		Optional:            true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"start": schema.StringAttribute{
+					MarkdownDescription: "First address of the pool, together with `end`",
+					Optional:            true,
+				},
+				"end": schema.StringAttribute{
+					MarkdownDescription: "Last address of the pool, together with `start`",
+					Optional:            true,
+				},
+				"cidr": schema.StringAttribute{
+					MarkdownDescription: "Pool as a network in CIDR notation, instead of `start` / `end`",
+					Optional:            true,
+				},
+			},
+		},
+	}
+}
+
+// prefixRange returns the first and last address of a prefix.
+func prefixRange(p netip.Prefix) addrRange {
+	p = p.Masked()
+	first := p.Addr()
+
+	b := first.As16()
+	hostBits := first.BitLen() - p.Bits()
+	for i := 15; hostBits > 0; i-- {
+		if hostBits >= 8 {
+			b[i] = 0xff
+			hostBits -= 8
+		} else {
+			b[i] |= byte(1<<hostBits - 1)
+			hostBits = 0
+		}
+	}
+
+	last := netip.AddrFrom16(b)
+	if first.Is4() {
+		last = last.Unmap()
+	}
+	return addrRange{first: first, last: last}
+}
+
+// parseKeaPool parses a pool as stored by OPNsense: "a-b", "a - b" or a CIDR.
+func parseKeaPool(s string) (addrRange, error) {
+	s = strings.TrimSpace(s)
+	if from, to, ok := strings.Cut(s, "-"); ok {
+		first, err := netip.ParseAddr(strings.TrimSpace(from))
+		if err != nil {
+			return addrRange{}, fmt.Errorf("invalid pool start %q", from)
+		}
+		last, err := netip.ParseAddr(strings.TrimSpace(to))
+		if err != nil {
+			return addrRange{}, fmt.Errorf("invalid pool end %q", to)
+		}
+		return addrRange{first: first, last: last}, nil
+	}
+
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return addrRange{}, fmt.Errorf("invalid pool %q", s)
+	}
+	return prefixRange(p), nil
+}
+
+// splitKeaPools splits the pools field, which holds one pool per line.
+func splitKeaPools(v string) []string {
+	var pools []string
+	for _, pool := range strings.FieldsFunc(v, func(r rune) bool { return r == '\n' || r == ',' }) {
+		if pool = strings.TrimSpace(pool); pool != "" {
+			pools = append(pools, pool)
+		}
+	}
+	return pools
+}
+
+// keaPoolRanges returns the ranges of the known, well-formed pools of list,
+// keyed by list index.
+func keaPoolRanges(ctx context.Context, list types.List, diags *diag.Diagnostics) map[int]addrRange {
+	ranges := map[int]addrRange{}
+	if list.IsNull() || list.IsUnknown() {
+		return ranges
+	}
+
+	var pools []keaPoolModel
+	diags.Append(list.ElementsAs(ctx, &pools, false)...)
+	for i, pool := range pools {
+		if pool.Start.IsUnknown() || pool.End.IsUnknown() || pool.CIDR.IsUnknown() {
+			continue
+		}
+		if r, err := parseKeaPool(keaPoolString(pool)); err == nil {
+			ranges[i] = r
+		}
+	}
+	return ranges
+}
+
+// validateKeaPools checks that every pool is well-formed, lies inside subnet
+// and doesn't overlap another pool.
+func validateKeaPools(ctx context.Context, list types.List, subnet types.String, p path.Path, diags *diag.Diagnostics) {
+	if list.IsNull() || list.IsUnknown() {
+		return
+	}
+
+	var pools []keaPoolModel
+	diags.Append(list.ElementsAs(ctx, &pools, false)...)
+	if diags.HasError() {
+		return
+	}
+
+	var network netip.Prefix
+	if !subnet.IsNull() && !subnet.IsUnknown() {
+		network, _ = netip.ParsePrefix(subnet.ValueString())
+	}
+
+	ranges := map[int]addrRange{}
+	for i, pool := range pools {
+		if pool.Start.IsUnknown() || pool.End.IsUnknown() || pool.CIDR.IsUnknown() {
+			continue
+		}
+
+		hasRange := !pool.Start.IsNull() || !pool.End.IsNull()
+		if hasRange == !pool.CIDR.IsNull() || pool.Start.IsNull() != pool.End.IsNull() {
+			diags.AddAttributeError(p.AtListIndex(i), "Invalid Pool",
+				"Set either both `start` and `end`, or `cidr`.")
+			continue
+		}
+
+		r, err := parseKeaPool(keaPoolString(pool))
+		if err != nil {
+			diags.AddAttributeError(p.AtListIndex(i), "Invalid Pool", err.Error()+".")
+			continue
+		}
+		if r.first.Is4() != r.last.Is4() || r.first.Compare(r.last) > 0 {
+			diags.AddAttributeError(p.AtListIndex(i), "Invalid Pool",
+				fmt.Sprintf("Pool %s must run from a lower to a higher address of the same family.", r))
+			continue
+		}
+		if network.IsValid() && !(network.Contains(r.first) && network.Contains(r.last)) {
+			diags.AddAttributeError(p.AtListIndex(i), "Pool Outside Subnet",
+				fmt.Sprintf("Pool %s is not inside subnet %s.", r, subnet.ValueString()))
+			continue
+		}
+		ranges[i] = r
+	}
+
+	for i := range pools {
+		for j := i + 1; j < len(pools); j++ {
+			a, okA := ranges[i]
+			b, okB := ranges[j]
+			if okA && okB && a.overlaps(b) {
+				diags.AddAttributeError(p.AtListIndex(j), "Overlapping Pools",
+					fmt.Sprintf("Pool %s overlaps pool %s.", b, a))
+			}
+		}
+	}
+}
+
+// keaPoolString renders a pool the way the pools field stores it.
+func keaPoolString(pool keaPoolModel) string {
+	if !pool.CIDR.IsNull() {
+		return pool.CIDR.ValueString()
+	}
+	return pool.Start.ValueString() + "-" + pool.End.ValueString()
+}
+
+// keaPoolsPayload renders pools one per line.
+func keaPoolsPayload(ctx context.Context, list types.List, diags *diag.Diagnostics) string {
+	if list.IsNull() || list.IsUnknown() {
+		return ""
+	}
+
+	var pools []keaPoolModel
+	diags.Append(list.ElementsAs(ctx, &pools, false)...)
+
+	lines := make([]string, 0, len(pools))
+	for _, pool := range pools {
+		lines = append(lines, keaPoolString(pool))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// keaPoolsFromAPI parses the pools field back into pool objects.
+func keaPoolsFromAPI(ctx context.Context, current types.List, v string, diags *diag.Diagnostics) types.List {
+	poolType := types.ObjectType{AttrTypes: keaPoolAttrTypes}
+
+	values := splitKeaPools(v)
+	if len(values) == 0 && current.IsNull() {
+		return types.ListNull(poolType)
+	}
+
+	pools := make([]keaPoolModel, 0, len(values))
+	for _, value := range values {
+		if from, to, ok := strings.Cut(value, "-"); ok {
+			pools = append(pools, keaPoolModel{
+				Start: types.StringValue(strings.TrimSpace(from)),
+				End:   types.StringValue(strings.TrimSpace(to)),
+				CIDR:  types.StringNull(),
+			})
+		} else {
+			pools = append(pools, keaPoolModel{
+				Start: types.StringNull(),
+				End:   types.StringNull(),
+				CIDR:  types.StringValue(value),
+			})
+		}
+	}
+
+	list, d := types.ListValueFrom(ctx, poolType, pools)
+	diags.Append(d...)
+	return list
+}
+
+// checkKeaPoolReservations rejects pools containing addresses that are already
+// reserved in the given service ("dhcpv4" or "dhcpv6"), Kea would otherwise
+// hand them out dynamically as well.
+func checkKeaPoolReservations(ctx context.Context, client *Client, service string, pools types.List, p path.Path, diags *diag.Diagnostics) {
+	ranges := keaPoolRanges(ctx, pools, diags)
+	if len(ranges) == 0 {
+		return
+	}
+
+	rows, err := client.searchItems(ctx, "kea/"+service+"/search_reservation")
+	if err != nil {
+		diags.AddWarning("Reservation Check Skipped", fmt.Sprintf("Unable to list reservations: %s", err))
+		return
+	}
+
+	for _, row := range rows {
+		ip, err := netip.ParseAddr(stringField(row, "ip_address"))
+		if err != nil {
+			continue
+		}
+		// v4 reservations match on MAC address, v6 reservations on DUID
+		owner := stringField(row, "hw_address")
+		if owner == "" {
+			owner = stringField(row, "duid")
+		}
+		for i := 0; i < len(pools.Elements()); i++ {
+			if pool, ok := ranges[i]; ok && pool.contains(ip) {
+				diags.AddAttributeError(p.AtListIndex(i), "Pool Contains Reservation",
+					fmt.Sprintf("Pool %s contains %s, which is reserved for %s. Move the reservation or shrink the pool.",
+						pool, ip, owner))
+			}
+		}
+	}
+}
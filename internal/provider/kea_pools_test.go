@@ -0,0 +1,280 @@
+package provider
+
+import (
+	"context"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestPrefixRange(t *testing.T) {
+	tests := []struct {
+		prefix      string
+		first, last string
+	}{
+		{"10.0.10.0/24", "10.0.10.0", "10.0.10.255"},
+		{"10.0.10.77/24", "10.0.10.0", "10.0.10.255"},
+		{"10.0.10.128/25", "10.0.10.128", "10.0.10.255"},
+		{"10.0.10.4/30", "10.0.10.4", "10.0.10.7"},
+		{"10.0.10.4/31", "10.0.10.4", "10.0.10.5"},
+		{"10.0.10.4/32", "10.0.10.4", "10.0.10.4"},
+		{"172.16.0.0/12", "172.16.0.0", "172.31.255.255"},
+		{"0.0.0.0/0", "0.0.0.0", "255.255.255.255"},
+		{"2001:db8::/64", "2001:db8::", "2001:db8::ffff:ffff:ffff:ffff"},
+		{"2001:db8::1000/116", "2001:db8::1000", "2001:db8::1fff"},
+		{"2001:db8::1/128", "2001:db8::1", "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		r := prefixRange(netip.MustParsePrefix(tt.prefix))
+		if r.first.String() != tt.first || r.last.String() != tt.last {
+			t.Errorf("prefixRange(%s) = %s, want %s-%s", tt.prefix, r, tt.first, tt.last)
+		}
+	}
+}
+
+func TestParseKeaPool(t *testing.T) {
+	tests := []struct {
+		pool    string
+		want    string
+		wantErr bool
+	}{
+		{"10.0.10.100-10.0.10.200", "10.0.10.100-10.0.10.200", false},
+		{"10.0.10.100 - 10.0.10.200", "10.0.10.100-10.0.10.200", false},
+		{" 10.0.10.128/25 ", "10.0.10.128-10.0.10.255", false},
+		{"10.0.10.9/32", "10.0.10.9-10.0.10.9", false},
+		{"2001:db8::100-2001:db8::1ff", "2001:db8::100-2001:db8::1ff", false},
+		{"2001:db8::/120", "2001:db8::-2001:db8::ff", false},
+		// Order is checked by validateKeaPools, not while parsing
+		{"10.0.10.200-10.0.10.100", "10.0.10.200-10.0.10.100", false},
+		{"10.0.10.100-", "", true},
+		{"-10.0.10.200", "", true},
+		{"10.0.10.100-host", "", true},
+		{"10.0.10.0/33", "", true},
+		{"10.0.10.1", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		r, err := parseKeaPool(tt.pool)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseKeaPool(%q) error = %v, wantErr %v", tt.pool, err, tt.wantErr)
+			continue
+		}
+		if err == nil && r.String() != tt.want {
+			t.Errorf("parseKeaPool(%q) = %s, want %s", tt.pool, r, tt.want)
+		}
+	}
+}
+
+func TestSplitKeaPools(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		{"10.0.10.100-10.0.10.200", []string{"10.0.10.100-10.0.10.200"}},
+		{"10.0.10.100-10.0.10.149\n10.0.10.150/31\n", []string{"10.0.10.100-10.0.10.149", "10.0.10.150/31"}},
+		{"10.0.10.100 - 10.0.10.149, 10.0.10.160/28", []string{"10.0.10.100 - 10.0.10.149", "10.0.10.160/28"}},
+		{"\n \n", nil},
+	}
+
+	for _, tt := range tests {
+		if got := splitKeaPools(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitKeaPools(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestAddrRange(t *testing.T) {
+	pool := addrRange{first: netip.MustParseAddr("10.0.10.100"), last: netip.MustParseAddr("10.0.10.200")}
+
+	for addr, want := range map[string]bool{
+		"10.0.10.99":  false,
+		"10.0.10.100": true,
+		"10.0.10.150": true,
+		"10.0.10.200": true,
+		"10.0.10.201": false,
+	} {
+		if got := pool.contains(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("%s.contains(%s) = %v, want %v", pool, addr, got, want)
+		}
+	}
+
+	for other, want := range map[string]bool{
+		"10.0.10.0-10.0.10.99":    false,
+		"10.0.10.0-10.0.10.100":   true,
+		"10.0.10.200-10.0.10.250": true,
+		"10.0.10.201-10.0.10.250": false,
+		"10.0.10.120-10.0.10.130": true,
+	} {
+		o, err := parseKeaPool(other)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := pool.overlaps(o); got != want {
+			t.Errorf("%s.overlaps(%s) = %v, want %v", pool, o, got, want)
+		}
+	}
+}
+
+// keaPoolList builds a pools attribute value.
+func keaPoolList(t *testing.T, pools ...keaPoolModel) types.List {
+	t.Helper()
+	list, diags := types.ListValueFrom(context.Background(), types.ObjectType{AttrTypes: keaPoolAttrTypes}, pools)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return list
+}
+
+func rangePool(start, end string) keaPoolModel {
+	return keaPoolModel{Start: types.StringValue(start), End: types.StringValue(end), CIDR: types.StringNull()}
+}
+
+func cidrPool(cidr string) keaPoolModel {
+	return keaPoolModel{Start: types.StringNull(), End: types.StringNull(), CIDR: types.StringValue(cidr)}
+}
+
+func TestValidateKeaPools(t *testing.T) {
+	tests := []struct {
+		name   string
+		subnet string
+		pools  []keaPoolModel
+		// summaries of the expected errors, by pool index
+		want map[int]string
+	}{
+		{
+			name:   "range and cidr",
+			subnet: "10.0.10.0/24",
+			pools:  []keaPoolModel{rangePool("10.0.10.100", "10.0.10.149"), cidrPool("10.0.10.160/28")},
+		},
+		{
+			name:   "whole subnet",
+			subnet: "10.0.10.0/24",
+			pools:  []keaPoolModel{rangePool("10.0.10.0", "10.0.10.255")},
+		},
+		{
+			name:   "adjacent pools",
+			subnet: "10.0.10.0/24",
+			pools:  []keaPoolModel{rangePool("10.0.10.100", "10.0.10.149"), rangePool("10.0.10.150", "10.0.10.199")},
+		},
+		{
+			name:   "single address pool in /32",
+			subnet: "10.0.10.9/32",
+			pools:  []keaPoolModel{rangePool("10.0.10.9", "10.0.10.9")},
+		},
+		{
+			name:   "/31 pool",
+			subnet: "10.0.10.0/24",
+			pools:  []keaPoolModel{cidrPool("10.0.10.2/31")},
+		},
+		{
+			name:   "shared boundary",
+			subnet: "10.0.10.0/24",
+			pools:  []keaPoolModel{rangePool("10.0.10.100", "10.0.10.150"), rangePool("10.0.10.150", "10.0.10.199")},
+			want:   map[int]string{1: "Overlapping Pools"},
+		},
+		{
+			name:   "cidr inside range",
+			subnet: "10.0.10.0/24",
+			pools:  []keaPoolModel{rangePool("10.0.10.100", "10.0.10.199"), cidrPool("10.0.10.128/26")},
+			want:   map[int]string{1: "Overlapping Pools"},
+		},
+		{
+			name:   "one past the subnet",
+			subnet: "10.0.10.0/25",
+			pools:  []keaPoolModel{rangePool("10.0.10.100", "10.0.10.128")},
+			want:   map[int]string{0: "Pool Outside Subnet"},
+		},
+		{
+			name:   "reversed",
+			subnet: "10.0.10.0/24",
+			pools:  []keaPoolModel{rangePool("10.0.10.200", "10.0.10.100")},
+			want:   map[int]string{0: "Invalid Pool"},
+		},
+		{
+			name:   "mixed families",
+			subnet: "10.0.10.0/24",
+			pools:  []keaPoolModel{rangePool("10.0.10.100", "2001:db8::1")},
+			want:   map[int]string{0: "Invalid Pool"},
+		},
+		{
+			name:   "start without end",
+			subnet: "10.0.10.0/24",
+			pools:  []keaPoolModel{{Start: types.StringValue("10.0.10.100"), End: types.StringNull(), CIDR: types.StringNull()}},
+			want:   map[int]string{0: "Invalid Pool"},
+		},
+		{
+			name:   "range and cidr in one pool",
+			subnet: "10.0.10.0/24",
+			pools:  []keaPoolModel{{Start: types.StringValue("10.0.10.100"), End: types.StringValue("10.0.10.110"), CIDR: types.StringValue("10.0.10.0/28")}},
+			want:   map[int]string{0: "Invalid Pool"},
+		},
+		{
+			name:   "unparseable",
+			subnet: "10.0.10.0/24",
+			pools:  []keaPoolModel{cidrPool("10.0.10.0/33"), rangePool("10.0.10.100", "10.0.10.110")},
+			want:   map[int]string{0: "Invalid Pool"},
+		},
+		{
+			name:   "v6",
+			subnet: "2001:db8::/64",
+			pools:  []keaPoolModel{rangePool("2001:db8::100", "2001:db8::1ff"), cidrPool("2001:db8:1::/120")},
+			want:   map[int]string{1: "Pool Outside Subnet"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			validateKeaPools(context.Background(), keaPoolList(t, tt.pools...), types.StringValue(tt.subnet), path.Root("pools"), &diags)
+
+			got := map[int]string{}
+			for _, d := range diags.Errors() {
+				withPath, ok := d.(diag.DiagnosticWithPath)
+				if !ok {
+					t.Fatalf("error without path: %s", d.Summary())
+				}
+				for i := range tt.pools {
+					if withPath.Path().Equal(path.Root("pools").AtListIndex(i)) {
+						got[i] = d.Summary()
+					}
+				}
+			}
+			if len(got) == 0 {
+				got = nil
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeaPoolsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	var diags diag.Diagnostics
+
+	pools := keaPoolList(t, rangePool("10.0.10.100", "10.0.10.149"), cidrPool("10.0.10.160/28"))
+	payload := keaPoolsPayload(ctx, pools, &diags)
+	if payload != "10.0.10.100-10.0.10.149\n10.0.10.160/28" {
+		t.Errorf("keaPoolsPayload = %q", payload)
+	}
+
+	back := keaPoolsFromAPI(ctx, types.ListNull(types.ObjectType{AttrTypes: keaPoolAttrTypes}), payload, &diags)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if !back.Equal(pools) {
+		t.Errorf("keaPoolsFromAPI(%q) = %s, want %s", payload, back, pools)
+	}
+
+	if empty := keaPoolsFromAPI(ctx, types.ListNull(types.ObjectType{AttrTypes: keaPoolAttrTypes}), "", &diags); !empty.IsNull() {
+		t.Errorf("keaPoolsFromAPI(\"\") = %s, want null", empty)
+	}
+}
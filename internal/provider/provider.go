@@ -1,11 +1,17 @@
 package provider
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -224,6 +230,8 @@ func (p *opnsenseProvider) Configure(ctx context.Context, req provider.Configure
 func (p *opnsenseProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewFirewallRuleDataSource,
+		NewFirewallAliasTableDataSource,
+		NewKeaLeasesDataSource,
 	}
 }
 
@@ -231,11 +239,22 @@ func (p *opnsenseProvider) DataSources(_ context.Context) []func() datasource.Da
 func (p *opnsenseProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewFirewallRuleResource,
+		NewFirewallRuleOrderResource,
 		NewFirewallAliasResource,
+		NewFirewallAliasEntryResource,
+		NewFirewallAliasBundleResource,
+		NewFirewallAliasSettingsResource,
 		NewFirewallCategoryResource,
 		NewNatDestinationResource,
+		NewNatSourceResource,
+		NewNatOneToOneResource,
+		NewNatNptResource,
 		NewKeaReservationResource,
 		NewKeaSubnetResource,
+		NewKeaSubnet6Resource,
+		NewKeaReservation6Resource,
+		NewKeaDhcpv4SettingsResource,
+		NewKeaHAPeerResource,
 		NewWireguardServerResource,
 		NewWireguardPeerResource,
 	}
@@ -247,6 +266,7 @@ type Client struct {
 	ApiKey    string
 	ApiSecret string
 	client    *http.Client
+	refs      *referenceCache
 }
 
 // NewClient creates a new OPNsense API client
@@ -265,22 +285,45 @@ func NewClient(host, apiKey, apiSecret *string, insecure bool, timeout int64) (*
 		ApiKey:    *apiKey,
 		ApiSecret: *apiSecret,
 		client:    httpClient,
+		refs:      newReferenceCache(),
 	}
 
 	return c, nil
 }
 
-// DoRequest performs an HTTP request to the OPNsense API
+// APIError is returned by DoRequest when OPNsense answers with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// isNotFound reports whether err is an APIError for a missing object.
+func isNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// DoRequest performs an HTTP request to the OPNsense API and returns the raw
+// response body. The endpoint is relative to /api/ (e.g. "firewall/filter/apply").
 func (c *Client) DoRequest(ctx context.Context, method, endpoint string, body []byte) ([]byte, error) {
 	url := fmt.Sprintf("%s/api/%s", c.Host, endpoint)
-	
+
 	tflog.Debug(ctx, "Making API request", map[string]any{
 		"method":   method,
 		"endpoint": endpoint,
 		"url":      url,
 	})
 
-	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	var reader io.Reader
+	if len(body) > 0 {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
@@ -288,14 +331,10 @@ func (c *Client) DoRequest(ctx context.Context, method, endpoint string, body []
 	// Set basic auth
 	req.SetBasicAuth(c.ApiKey, c.ApiSecret)
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
+	// Only set Content-Type if we have a body, OPNsense rejects empty JSON posts
 	req.Header.Set("Accept", "application/json")
-
-	if body != nil && len(body) > 0 {
-		req.Body = http.NoBody
-		// For POST requests with body, we'd need to set the body properly
-		// This is simplified for the example
+	if len(body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
 	resp, err := c.client.Do(req)
@@ -304,13 +343,153 @@ func (c *Client) DoRequest(ctx context.Context, method, endpoint string, body []
 	}
 	defer resp.Body.Close()
 
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+
+	tflog.Debug(ctx, "API response", map[string]any{
+		"status_code": resp.StatusCode,
+		"body":        string(respBody),
+	})
+
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("API request failed with status %d", resp.StatusCode)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
-	// Read response body
-	var respBody []byte
-	// In a real implementation, you'd read the response body here
-	
 	return respBody, nil
 }
+
+// doJSON marshals payload (if any), performs the request and decodes the
+// JSON object OPNsense answers with.
+func (c *Client) doJSON(ctx context.Context, method, endpoint string, payload interface{}) (map[string]interface{}, error) {
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal payload: %w", err)
+		}
+	}
+
+	respBody, err := c.DoRequest(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{}
+	if len(bytes.TrimSpace(respBody)) == 0 {
+		return result, nil
+	}
+	// Some endpoints answer with an empty JSON array instead of an object
+	if bytes.HasPrefix(bytes.TrimSpace(respBody), []byte("[")) {
+		return result, nil
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("unable to parse response: %w\nRaw response: %s", err, string(respBody))
+	}
+	return result, nil
+}
+
+// getItem fetches a model item and returns the object stored under key,
+// e.g. {"rule": {...}} for key "rule". A nil map means the item is missing.
+func (c *Client) getItem(ctx context.Context, endpoint, key string) (map[string]interface{}, error) {
+	result, err := c.doJSON(ctx, "GET", endpoint, nil)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	item, ok := result[key].(map[string]interface{})
+	if !ok || len(item) == 0 {
+		return nil, nil
+	}
+	return item, nil
+}
+
+// addItem posts payload to an add endpoint and returns the new item UUID.
+func (c *Client) addItem(ctx context.Context, endpoint string, payload interface{}) (string, error) {
+	result, err := c.doJSON(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return "", err
+	}
+	if err := resultError(result); err != nil {
+		return "", err
+	}
+
+	uuid, ok := result["uuid"].(string)
+	if !ok || uuid == "" {
+		return "", fmt.Errorf("no UUID returned from API: %v", result)
+	}
+	return uuid, nil
+}
+
+// setItem posts payload to a set endpoint.
+func (c *Client) setItem(ctx context.Context, endpoint string, payload interface{}) error {
+	result, err := c.doJSON(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return err
+	}
+	return resultError(result)
+}
+
+// post calls an action endpoint without body (delItem, apply, reconfigure, ...).
+func (c *Client) post(ctx context.Context, endpoint string) error {
+	result, err := c.doJSON(ctx, "POST", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	return resultError(result)
+}
+
+// searchItems returns every row of a search endpoint (searchItem, search_rule, ...).
+func (c *Client) searchItems(ctx context.Context, endpoint string) ([]map[string]interface{}, error) {
+	payload := map[string]interface{}{
+		"current":      1,
+		"rowCount":     -1,
+		"searchPhrase": "",
+	}
+	result, err := c.doJSON(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	rawRows, _ := result["rows"].([]interface{})
+	rows := make([]map[string]interface{}, 0, len(rawRows))
+	for _, raw := range rawRows {
+		if row, ok := raw.(map[string]interface{}); ok {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// resultError converts a {"result": "failed", "validations": {...}} answer
+// into an error listing every validation message.
+func resultError(result map[string]interface{}) error {
+	status, _ := result["result"].(string)
+	if status != "failed" {
+		return nil
+	}
+
+	var errorMsgs []string
+	if validations, ok := result["validations"].(map[string]interface{}); ok {
+		for field, errs := range validations {
+			switch v := errs.(type) {
+			case []interface{}:
+				for _, e := range v {
+					errorMsgs = append(errorMsgs, fmt.Sprintf("%s: %v", field, e))
+				}
+			default:
+				errorMsgs = append(errorMsgs, fmt.Sprintf("%s: %v", field, v))
+			}
+		}
+	}
+	if len(errorMsgs) == 0 {
+		return fmt.Errorf("API returned failed status: %v", result)
+	}
+	sort.Strings(errorMsgs)
+	return fmt.Errorf("validation errors:\n- %s", strings.Join(errorMsgs, "\n- "))
+}
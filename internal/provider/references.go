@@ -0,0 +1,311 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Object kinds tracked by the reference cache.
+const (
+	refKindAlias    = "alias"
+	refKindCategory = "category"
+)
+
+// referenceSearchEndpoints maps an object kind to the search endpoint listing it.
+var referenceSearchEndpoints = map[string]string{
+	refKindAlias:    "firewall/alias/searchItem",
+	refKindCategory: "firewall/category/searchItem",
+}
+
+var (
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+	// Interface networks/addresses usable in rules without an alias
+	// (lan, lanip, opt3, opt3ip, wan, ...).
+	interfaceNetPattern = regexp.MustCompile(`^(lan|wan|opt[0-9]+|lo[0-9]*|openvpn|wireguard|ipsec|enc[0-9]+)(ip)?$`)
+
+	// Port values that are numbers or ranges ("443", "8000-8080", "8000:8080").
+	portPattern = regexp.MustCompile(`^[0-9]+([-:][0-9]+)?$`)
+)
+
+// wellKnownPorts are common service names pf resolves through /etc/services
+// and OPNsense accepts in port fields, they are not alias references.
+var wellKnownPorts = map[string]bool{
+	"ftp": true, "ssh": true, "telnet": true, "smtp": true, "domain": true,
+	"dns": true, "http": true, "https": true, "pop3": true, "imap": true,
+	"imaps": true, "pop3s": true, "ntp": true, "snmp": true, "ldap": true,
+	"ldaps": true, "submission": true, "smtps": true, "rdp": true, "syslog": true,
+}
+
+// referenceCache remembers objects existing on the firewall and objects
+// planned in the current run, so plan-time reference checks only hit the
+// API once per object kind.
+type referenceCache struct {
+	mu      sync.Mutex
+	planned map[string]map[string]bool
+	fetched map[string]map[string]string // kind -> name -> uuid
+}
+
+func newReferenceCache() *referenceCache {
+	return &referenceCache{
+		planned: map[string]map[string]bool{},
+		fetched: map[string]map[string]string{},
+	}
+}
+
+// registerPlanned records that an object of kind named name is part of the
+// plan. Terraform plans dependencies first, so a resource referencing it
+// (e.g. opnsense_firewall_alias.x.name) sees the registration.
+func (c *Client) registerPlanned(kind, name string) {
+	if name == "" {
+		return
+	}
+	c.refs.mu.Lock()
+	defer c.refs.mu.Unlock()
+	if c.refs.planned[kind] == nil {
+		c.refs.planned[kind] = map[string]bool{}
+	}
+	c.refs.planned[kind][name] = true
+}
+
+// isPlanned reports whether an object of kind named name was registered.
+func (c *Client) isPlanned(kind, name string) bool {
+	c.refs.mu.Lock()
+	defer c.refs.mu.Unlock()
+	return c.refs.planned[kind][name]
+}
+
+// lookupObjects returns name -> UUID for every object of kind on the firewall.
+func (c *Client) lookupObjects(ctx context.Context, kind string) (map[string]string, error) {
+	c.refs.mu.Lock()
+	defer c.refs.mu.Unlock()
+
+	if objects, ok := c.refs.fetched[kind]; ok {
+		return objects, nil
+	}
+
+	rows, err := c.searchItems(ctx, referenceSearchEndpoints[kind])
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make(map[string]string, len(rows))
+	for _, row := range rows {
+		objects[stringField(row, "name")] = stringField(row, "uuid")
+	}
+	c.refs.fetched[kind] = objects
+	return objects, nil
+}
+
+// invalidateObjects drops the cached object list of kind after a change.
+func (c *Client) invalidateObjects(kind string) {
+	c.refs.mu.Lock()
+	defer c.refs.mu.Unlock()
+	delete(c.refs.fetched, kind)
+}
+
+// objectExists reports whether ref (name or UUID) names an object of kind
+// that exists on the firewall or is planned in this run.
+func (c *Client) objectExists(ctx context.Context, kind, ref string) (bool, error) {
+	if c.isPlanned(kind, ref) {
+		return true, nil
+	}
+
+	objects, err := c.lookupObjects(ctx, kind)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := objects[ref]; ok {
+		return true, nil
+	}
+	for _, uuid := range objects {
+		if uuid == ref {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isUUID reports whether s looks like an OPNsense model UUID.
+func isUUID(s string) bool {
+	return uuidPattern.MatchString(s)
+}
+
+// networkAliasRefs returns the alias names referenced by a source/destination
+// network value. The value may be a comma-separated list; addresses,
+// networks, ranges and interface networks are not references.
+func networkAliasRefs(v string) []string {
+	var refs []string
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" || part == "any" || part == "(self)" || interfaceNetPattern.MatchString(part) {
+			continue
+		}
+		if net.ParseIP(part) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(part); err == nil {
+			continue
+		}
+		if from, to, ok := strings.Cut(part, "-"); ok && net.ParseIP(strings.TrimSpace(from)) != nil && net.ParseIP(strings.TrimSpace(to)) != nil {
+			continue
+		}
+		refs = append(refs, part)
+	}
+	return refs
+}
+
+// portAliasRef returns the alias name referenced by a port value, or "" for
+// port numbers, ranges and well-known service names. Other names may still
+// be services from /etc/services, so callers only warn when they are missing.
+func portAliasRef(v string) string {
+	v = strings.TrimSpace(v)
+	if v == "" || v == "any" || portPattern.MatchString(v) || wellKnownPorts[strings.ToLower(v)] {
+		return ""
+	}
+	return v
+}
+
+// resolveCategories turns category names into UUIDs. UUIDs are passed
+// through, unknown names are created with auto=1 so OPNsense removes them
+// again once nothing uses them, like the GUI does.
+func (c *Client) resolveCategories(ctx context.Context, refs []string) ([]string, error) {
+	uuids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref == "" {
+			continue
+		}
+		if isUUID(ref) {
+			uuids = append(uuids, ref)
+			continue
+		}
+
+		categories, err := c.lookupObjects(ctx, refKindCategory)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list categories: %w", err)
+		}
+		if uuid, ok := categories[ref]; ok {
+			uuids = append(uuids, uuid)
+			continue
+		}
+
+		payload := map[string]interface{}{
+			"category": map[string]interface{}{
+				"name": ref,
+				"auto": "1",
+			},
+		}
+		uuid, err := c.addItem(ctx, "firewall/category/addItem", payload)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create category %q: %w", ref, err)
+		}
+		tflog.Info(ctx, "Created category referenced by name", map[string]any{"name": ref, "uuid": uuid})
+		c.invalidateObjects(refKindCategory)
+		uuids = append(uuids, uuid)
+	}
+	return uuids, nil
+}
+
+// categoryRefsFromAPI maps category UUIDs read from OPNsense back onto the
+// references used in configuration: entries configured by name stay names,
+// entries configured by UUID stay UUIDs, and the configured order is kept
+// when nothing changed.
+func (c *Client) categoryRefsFromAPI(ctx context.Context, current []string, uuids []string) ([]string, error) {
+	categories, err := c.lookupObjects(ctx, refKindCategory)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(categories))
+	for name, uuid := range categories {
+		names[uuid] = name
+	}
+
+	byName := len(current) > 0
+	inCurrent := make(map[string]bool, len(current))
+	for _, ref := range current {
+		inCurrent[ref] = true
+		if isUUID(ref) {
+			byName = false
+		}
+	}
+
+	refs := make(map[string]bool, len(uuids))
+	for _, uuid := range uuids {
+		switch name := names[uuid]; {
+		case inCurrent[uuid]:
+			refs[uuid] = true
+		case name != "" && (inCurrent[name] || byName):
+			refs[name] = true
+		default:
+			refs[uuid] = true
+		}
+	}
+
+	result := make([]string, 0, len(refs))
+	for _, ref := range current {
+		if refs[ref] {
+			result = append(result, ref)
+			delete(refs, ref)
+		}
+	}
+	extra := make([]string, 0, len(refs))
+	for ref := range refs {
+		extra = append(extra, ref)
+	}
+	sort.Strings(extra)
+	return append(result, extra...), nil
+}
+
+// categoriesPayload resolves a categories attribute into the comma-separated
+// UUID list OPNsense expects. Null lists clear the categories.
+func (c *Client) categoriesPayload(ctx context.Context, categories types.List, diags *diag.Diagnostics) string {
+	if categories.IsNull() || categories.IsUnknown() {
+		return ""
+	}
+
+	var refs []string
+	diags.Append(categories.ElementsAs(ctx, &refs, false)...)
+	if diags.HasError() {
+		return ""
+	}
+
+	uuids, err := c.resolveCategories(ctx, refs)
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to resolve categories: %s", err))
+		return ""
+	}
+	return strings.Join(uuids, ",")
+}
+
+// categoriesFromAPI maps the categories option field of an item back onto a
+// categories attribute, see categoryRefsFromAPI.
+func (c *Client) categoriesFromAPI(ctx context.Context, current types.List, field interface{}, diags *diag.Diagnostics) types.List {
+	uuids := selectedOptions(field)
+	if len(uuids) == 0 && current.IsNull() {
+		return current
+	}
+
+	var refs []string
+	if !current.IsNull() && !current.IsUnknown() {
+		diags.Append(current.ElementsAs(ctx, &refs, false)...)
+	}
+
+	refs, err := c.categoryRefsFromAPI(ctx, refs, uuids)
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to list categories: %s", err))
+		return current
+	}
+
+	categories, d := types.ListValueFrom(ctx, types.StringType, refs)
+	diags.Append(d...)
+	return categories
+}
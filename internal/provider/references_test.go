@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNetworkAliasRefs(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		{"any", nil},
+		{"(self)", nil},
+		{"lan", nil},
+		{"opt3ip", nil},
+		{"10.0.0.1", nil},
+		{"10.0.0.0/24", nil},
+		{"2001:db8::/64", nil},
+		{"10.0.0.1-10.0.0.9", nil},
+		{"10.0.0.1 - 10.0.0.9", nil},
+		{"web_servers", []string{"web_servers"}},
+		{"10.0.0.0/24,web_servers", []string{"web_servers"}},
+		{"web_servers, db_servers", []string{"web_servers", "db_servers"}},
+		{"lan,10.0.0.1,", nil},
+	}
+
+	for _, tt := range tests {
+		if got := networkAliasRefs(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("networkAliasRefs(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPortAliasRef(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"any", ""},
+		{"443", ""},
+		{"8000-8080", ""},
+		{"8000:8080", ""},
+		{"https", ""},
+		{"LDAPS", ""},
+		{"syslog", ""},
+		{"web_ports", "web_ports"},
+		// Not in the well-known list; ModifyPlan only warns when missing
+		{"bgp", "bgp"},
+	}
+
+	for _, tt := range tests {
+		if got := portAliasRef(tt.value); got != tt.want {
+			t.Errorf("portAliasRef(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
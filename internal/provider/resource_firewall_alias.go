@@ -2,23 +2,26 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var _ resource.Resource = &FirewallAliasResource{}
 var _ resource.ResourceWithImportState = &FirewallAliasResource{}
+var _ resource.ResourceWithModifyPlan = &FirewallAliasResource{}
+var _ resource.ResourceWithValidateConfig = &FirewallAliasResource{}
 
 func NewFirewallAliasResource() resource.Resource {
 	return &FirewallAliasResource{}
@@ -32,9 +35,32 @@ type FirewallAliasResourceModel struct {
 	ID          types.String `tfsdk:"id"`
 	Name        types.String `tfsdk:"name"`
 	Type        types.String `tfsdk:"type"`
-	Content     types.List   `tfsdk:"content"`
+	Content     types.Set    `tfsdk:"content"`
+	Entries     types.Set    `tfsdk:"entries"`
 	Description types.String `tfsdk:"description"`
 	Enabled     types.Bool   `tfsdk:"enabled"`
+	Categories  types.List   `tfsdk:"categories"`
+
+	// Type specific options
+	Proto          types.Set     `tfsdk:"proto"`
+	UpdateFreq     types.Float64 `tfsdk:"update_freq"`
+	Counters       types.Bool    `tfsdk:"counters"`
+	Interface      types.String  `tfsdk:"interface"`
+	PathExpression types.String  `tfsdk:"path_expression"`
+	AuthType       types.String  `tfsdk:"auth_type"`
+	Username       types.String  `tfsdk:"username"`
+	Password       types.String  `tfsdk:"password"`
+}
+
+// aliasEntryModel is a content entry with its description.
+type aliasEntryModel struct {
+	Address     types.String `tfsdk:"address"`
+	Description types.String `tfsdk:"description"`
+}
+
+var aliasEntryAttrTypes = map[string]attr.Type{
+	"address":     types.StringType,
+	"description": types.StringType,
 }
 
 func (r *FirewallAliasResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -54,18 +80,37 @@ func (r *FirewallAliasResource) Schema(ctx context.Context, req resource.SchemaR
 				},
 			},
 			"name": schema.StringAttribute{
-				MarkdownDescription: "Name of the alias",
+				MarkdownDescription: "Name of the alias (letters, digits and underscores, at most 31 characters)",
 				Required:            true,
 			},
 			"type": schema.StringAttribute{
-				MarkdownDescription: "Type of alias (host, network, port, url, urltable, geoip, networkgroup, mac, external, etc.)",
+				MarkdownDescription: "Type of alias: " + strings.Join(aliasTypes, ", "),
 				Required:            true,
+				Validators: []validator.String{
+					stringOneOf(aliasTypes...),
+				},
 			},
-			"content": schema.ListAttribute{
-				MarkdownDescription: "List of alias entries (IPs, networks, ports, etc.)",
-				Required:            true,
+			"content": schema.SetAttribute{
+				MarkdownDescription: "Set of alias entries, validated against `type` (IPs, networks, ports, URLs, country codes, MAC addresses, AS numbers, ...). Order doesn't matter and equivalent spellings (`10.0.0.1/32` and `10.0.0.1`) don't cause diffs. Must be empty for `external` aliases. Conflicts with `entries`. When neither is set the content isn't managed",
+				Optional:            true,
 				ElementType:         types.StringType,
 			},
+			"entries": schema.SetNestedAttribute{
+				MarkdownDescription: "Alias entries with a description each, alternative to `content`",
+				Optional:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"address": schema.StringAttribute{
+							MarkdownDescription: "Entry, validated like `content`",
+							Required:            true,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "Description of the entry",
+							Optional:            true,
+						},
+					},
+				},
+			},
 			"description": schema.StringAttribute{
 				MarkdownDescription: "Description of the alias",
 				Optional:            true,
@@ -74,6 +119,48 @@ func (r *FirewallAliasResource) Schema(ctx context.Context, req resource.SchemaR
 				MarkdownDescription: "Whether the alias is enabled",
 				Optional:            true,
 			},
+			"categories": schema.ListAttribute{
+				MarkdownDescription: "List of category names or UUIDs. Names that don't exist yet are created as automatic categories",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"proto": schema.SetAttribute{
+				MarkdownDescription: "Address families to load for `geoip` and `asn` aliases: 'IPv4', 'IPv6'",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"update_freq": schema.Float64Attribute{
+				MarkdownDescription: "Refresh frequency in days for `urltable` and `urljson` aliases (e.g., 0.5 for every 12 hours)",
+				Optional:            true,
+			},
+			"counters": schema.BoolAttribute{
+				MarkdownDescription: "Collect pf table statistics for this alias",
+				Optional:            true,
+			},
+			"interface": schema.StringAttribute{
+				MarkdownDescription: "Interface providing the IPv6 prefix of a `dynipv6host` alias (required for that type)",
+				Optional:            true,
+			},
+			"path_expression": schema.StringAttribute{
+				MarkdownDescription: "jq expression selecting the addresses of a `urljson` alias (required for that type)",
+				Optional:            true,
+			},
+			"auth_type": schema.StringAttribute{
+				MarkdownDescription: "Authentication for URL based aliases: 'Basic' or 'Bearer'",
+				Optional:            true,
+				Validators: []validator.String{
+					stringOneOf("Basic", "Bearer"),
+				},
+			},
+			"username": schema.StringAttribute{
+				MarkdownDescription: "Username for `auth_type = \"Basic\"`",
+				Optional:            true,
+			},
+			"password": schema.StringAttribute{
+				MarkdownDescription: "Password (Basic) or token (Bearer) for URL based aliases. Not read back from OPNsense",
+				Optional:            true,
+				Sensitive:           true,
+			},
 		},
 	}
 }
@@ -95,117 +182,123 @@ func (r *FirewallAliasResource) Configure(ctx context.Context, req resource.Conf
 	r.client = client
 }
 
-func (r *FirewallAliasResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+// ValidateConfig checks the name, every content entry against the alias type
+// and that type specific options are only set where OPNsense uses them.
+func (r *FirewallAliasResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
 	var data FirewallAliasResourceModel
-
-	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Convert content list to newline-separated string (not comma-separated!)
-	var contentItems []string
-	resp.Diagnostics.Append(data.Content.ElementsAs(ctx, &contentItems, false)...)
-	contentStr := strings.Join(contentItems, "\n")  // Changed from "," to "\n"
-
-	aliasData := map[string]interface{}{
-		"alias": map[string]interface{}{
-			"name":    data.Name.ValueString(),
-			"type":    data.Type.ValueString(),
-			"content": contentStr,
-		},
+	if !data.Name.IsUnknown() && !data.Name.IsNull() && !aliasNamePattern.MatchString(data.Name.ValueString()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Invalid Alias Name",
+			fmt.Sprintf("Alias name %q may only contain letters, digits and underscores and be at most 31 characters long.", data.Name.ValueString()),
+		)
 	}
 
-	if !data.Description.IsNull() {
-		aliasData["alias"].(map[string]interface{})["description"] = data.Description.ValueString()
-	}
-	if !data.Enabled.IsNull() {
-		if data.Enabled.ValueBool() {
-			aliasData["alias"].(map[string]interface{})["enabled"] = "1"
-		} else {
-			aliasData["alias"].(map[string]interface{})["enabled"] = "0"
-		}
-	} else {
-		aliasData["alias"].(map[string]interface{})["enabled"] = "1"
+	if data.Type.IsUnknown() || data.Type.IsNull() {
+		return
 	}
+	aliasType := data.Type.ValueString()
 
-	jsonData, _ := json.Marshal(aliasData)
-
-	url := fmt.Sprintf("%s/api/firewall/alias/addItem", r.client.Host)
-	httpReq, _ := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonData)))
-	httpReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	httpResp, err := r.client.client.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create alias: %s", err))
+	if !data.Content.IsNull() && !data.Entries.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("entries"),
+			"Conflicting Attributes",
+			"Only one of `content` or `entries` can be set.",
+		)
 		return
 	}
-	defer httpResp.Body.Close()
-
-	body, _ := io.ReadAll(httpResp.Body)
 
-	// Debug: Log raw response
-	tflog.Debug(ctx, "Raw API Response", map[string]any{
-		"status_code": httpResp.StatusCode,
-		"body":        string(body),
-	})
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response: %s. Body: %s", err, string(body)))
-		return
+	checkEntry := func(p path.Path, entry types.String) {
+		if entry.IsUnknown() || entry.IsNull() {
+			return
+		}
+		if problem := validateAliasEntry(aliasType, entry.ValueString()); problem != "" {
+			resp.Diagnostics.AddAttributeError(
+				p,
+				"Invalid Alias Entry",
+				fmt.Sprintf("Entry %q is not valid for a %s alias: %s.", entry.ValueString(), aliasType, problem),
+			)
+		}
+	}
+	if !data.Content.IsUnknown() && !data.Content.IsNull() {
+		var entries []types.String
+		resp.Diagnostics.Append(data.Content.ElementsAs(ctx, &entries, false)...)
+		for _, entry := range entries {
+			checkEntry(path.Root("content").AtSetValue(entry), entry)
+		}
+	}
+	if !data.Entries.IsUnknown() && !data.Entries.IsNull() {
+		var entries []aliasEntryModel
+		resp.Diagnostics.Append(data.Entries.ElementsAs(ctx, &entries, false)...)
+		for _, entry := range entries {
+			checkEntry(path.Root("entries"), entry.Address)
+		}
 	}
 
-	// Try multiple possible response formats
-	var uuid string
-	
-	// Format 1: {"uuid": "..."}
-	if val, ok := result["uuid"].(string); ok && val != "" {
-		uuid = val
+	optional := map[string]bool{
+		"proto":           !data.Proto.IsNull(),
+		"update_freq":     !data.UpdateFreq.IsNull(),
+		"interface":       !data.Interface.IsNull(),
+		"path_expression": !data.PathExpression.IsNull(),
+		"auth_type":       !data.AuthType.IsNull(),
+		"username":        !data.Username.IsNull(),
+		"password":        !data.Password.IsNull(),
 	}
-	
-	// Format 2: {"result": "saved", "uuid": "..."}
-	if uuid == "" {
-		if val, ok := result["uuid"].(string); ok && val != "" {
-			uuid = val
+	for attribute, set := range optional {
+		if !set {
+			continue
 		}
+		if problem := aliasFieldMismatch(attribute, aliasType); problem != "" {
+			resp.Diagnostics.AddAttributeError(path.Root(attribute), "Invalid Attribute Combination", problem+".")
+		}
+	}
+
+	if aliasType == "dynipv6host" && data.Interface.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("interface"), "Missing Attribute", "`interface` is required for dynipv6host aliases.")
+	}
+	if aliasType == "urljson" && data.PathExpression.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("path_expression"), "Missing Attribute", "`path_expression` is required for urljson aliases.")
 	}
-	
-	// Format 3: Check if there's a nested structure
-	if uuid == "" {
-		if alias, ok := result["alias"].(map[string]interface{}); ok {
-			if val, ok := alias["uuid"].(string); ok && val != "" {
-				uuid = val
+
+	if !data.Proto.IsUnknown() && !data.Proto.IsNull() {
+		var protos []string
+		resp.Diagnostics.Append(data.Proto.ElementsAs(ctx, &protos, false)...)
+		for _, proto := range protos {
+			if proto != "IPv4" && proto != "IPv6" {
+				resp.Diagnostics.AddAttributeError(path.Root("proto"), "Invalid Attribute Value", fmt.Sprintf("Got %q, value must be one of: IPv4, IPv6", proto))
 			}
 		}
 	}
+}
 
-	// Format 4: Maybe it returns the data back with UUID embedded
-	if uuid == "" {
-		// Log what we got to help debug
-		tflog.Warn(ctx, "UUID not found in expected locations", map[string]any{
-			"response_keys": fmt.Sprintf("%v", getKeys(result)),
-			"full_response": string(body),
-		})
+func (r *FirewallAliasResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data FirewallAliasResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if uuid == "" {
-		resp.Diagnostics.AddError(
-			"API Error", 
-			fmt.Sprintf("No UUID returned from API. Response: %s", string(body)),
-		)
+	payload := r.mapToPayload(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	uuid, err := r.client.addItem(ctx, "firewall/alias/addItem", payload)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create alias: %s", err))
+		return
+	}
 	data.ID = types.StringValue(uuid)
 
-	// Apply configuration
-	applyURL := fmt.Sprintf("%s/api/firewall/alias/reconfigure", r.client.Host)
-	applyReq, _ := http.NewRequestWithContext(ctx, "POST", applyURL, nil)
-	applyReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	r.client.client.Do(applyReq)
+	applyAliases(ctx, r.client)
+	r.client.invalidateObjects(refKindAlias)
 
+	r.refresh(ctx, &data, &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -216,19 +309,10 @@ func (r *FirewallAliasResource) Read(ctx context.Context, req resource.ReadReque
 		return
 	}
 
-	url := fmt.Sprintf("%s/api/firewall/alias/getItem/%s", r.client.Host, data.ID.ValueString())
-	httpReq, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
-	httpReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-
-	httpResp, err := r.client.client.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read alias: %s", err))
-		return
-	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode == 404 {
-		resp.State.RemoveResource(ctx)
+	if !r.refresh(ctx, &data, &resp.Diagnostics) {
+		if !resp.Diagnostics.HasError() {
+			resp.State.RemoveResource(ctx)
+		}
 		return
 	}
 
@@ -242,49 +326,20 @@ func (r *FirewallAliasResource) Update(ctx context.Context, req resource.UpdateR
 		return
 	}
 
-	var contentItems []string
-	resp.Diagnostics.Append(data.Content.ElementsAs(ctx, &contentItems, false)...)
-	contentStr := strings.Join(contentItems, "\n")  // Changed from "," to "\n"
-
-	aliasData := map[string]interface{}{
-		"alias": map[string]interface{}{
-			"name":    data.Name.ValueString(),
-			"type":    data.Type.ValueString(),
-			"content": contentStr,
-		},
-	}
-
-	if !data.Description.IsNull() {
-		aliasData["alias"].(map[string]interface{})["description"] = data.Description.ValueString()
-	}
-	if !data.Enabled.IsNull() {
-		if data.Enabled.ValueBool() {
-			aliasData["alias"].(map[string]interface{})["enabled"] = "1"
-		} else {
-			aliasData["alias"].(map[string]interface{})["enabled"] = "0"
-		}
+	payload := r.mapToPayload(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	jsonData, _ := json.Marshal(aliasData)
-
-	url := fmt.Sprintf("%s/api/firewall/alias/setItem/%s", r.client.Host, data.ID.ValueString())
-	httpReq, _ := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonData)))
-	httpReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	httpResp, err := r.client.client.Do(httpReq)
-	if err != nil {
+	if err := r.client.setItem(ctx, "firewall/alias/setItem/"+data.ID.ValueString(), payload); err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update alias: %s", err))
 		return
 	}
-	defer httpResp.Body.Close()
 
-	// Apply configuration
-	applyURL := fmt.Sprintf("%s/api/firewall/alias/reconfigure", r.client.Host)
-	applyReq, _ := http.NewRequestWithContext(ctx, "POST", applyURL, nil)
-	applyReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	r.client.client.Do(applyReq)
+	applyAliases(ctx, r.client)
+	r.client.invalidateObjects(refKindAlias)
 
+	r.refresh(ctx, &data, &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -295,33 +350,234 @@ func (r *FirewallAliasResource) Delete(ctx context.Context, req resource.DeleteR
 		return
 	}
 
-	url := fmt.Sprintf("%s/api/firewall/alias/delItem/%s", r.client.Host, data.ID.ValueString())
-	httpReq, _ := http.NewRequestWithContext(ctx, "POST", url, nil)
-	httpReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-
-	httpResp, err := r.client.client.Do(httpReq)
-	if err != nil {
+	if err := r.client.post(ctx, "firewall/alias/delItem/"+data.ID.ValueString()); err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete alias: %s", err))
 		return
 	}
-	defer httpResp.Body.Close()
 
-	// Apply configuration
-	applyURL := fmt.Sprintf("%s/api/firewall/alias/reconfigure", r.client.Host)
-	applyReq, _ := http.NewRequestWithContext(ctx, "POST", applyURL, nil)
-	applyReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	r.client.client.Do(applyReq)
+	applyAliases(ctx, r.client)
+	r.client.invalidateObjects(refKindAlias)
+}
+
+// ModifyPlan registers the planned name so firewall rules referencing it
+// pass their plan-time reference checks before it exists.
+func (r *FirewallAliasResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var name types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("name"), &name)...)
+	if resp.Diagnostics.HasError() || name.IsUnknown() || name.IsNull() {
+		return
+	}
+	r.client.registerPlanned(refKindAlias, name.ValueString())
 }
 
 func (r *FirewallAliasResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
 
-// Helper function to get keys from map for debugging
-func getKeys(m map[string]interface{}) []string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
+func (r *FirewallAliasResource) mapToPayload(ctx context.Context, data *FirewallAliasResourceModel, diags *diag.Diagnostics) map[string]interface{} {
+	// Content is newline-separated, not comma-separated!
+	var contentItems, descriptions []string
+	hasDescriptions := false
+	for _, entry := range r.configuredEntries(ctx, data, diags) {
+		contentItems = append(contentItems, entry.address)
+		descriptions = append(descriptions, entry.description)
+		hasDescriptions = hasDescriptions || entry.description != ""
+	}
+
+	var protos []string
+	if !data.Proto.IsNull() {
+		diags.Append(data.Proto.ElementsAs(ctx, &protos, false)...)
+	}
+
+	alias := map[string]interface{}{
+		"name":            data.Name.ValueString(),
+		"type":            data.Type.ValueString(),
+		"description":     data.Description.ValueString(),
+		"enabled":         boolString(data.Enabled.IsNull() || data.Enabled.ValueBool()),
+		"proto":           joinSorted(protos),
+		"counters":        boolString(data.Counters.ValueBool()),
+		"interface":       data.Interface.ValueString(),
+		"path_expression": data.PathExpression.ValueString(),
+		"authtype":        data.AuthType.ValueString(),
+		"username":        data.Username.ValueString(),
+		"password":        data.Password.ValueString(),
+		"updatefreq":      "",
+	}
+
+	if !data.UpdateFreq.IsNull() && !data.UpdateFreq.IsUnknown() {
+		alias["updatefreq"] = strconv.FormatFloat(data.UpdateFreq.ValueFloat64(), 'f', -1, 64)
+	}
+
+	// Without content or entries the alias content is left alone
+	if !data.Content.IsNull() || !data.Entries.IsNull() {
+		alias["content"] = strings.Join(contentItems, "\n")
+	}
+
+	// Per-entry descriptions line up with content, only sent when used
+	if hasDescriptions {
+		alias["descriptions"] = strings.Join(descriptions, "\n")
+	}
+
+	alias["categories"] = r.client.categoriesPayload(ctx, data.Categories, diags)
+
+	return map[string]interface{}{"alias": alias}
+}
+
+// refresh reads the alias back into data, returning false when it no longer exists.
+func (r *FirewallAliasResource) refresh(ctx context.Context, data *FirewallAliasResourceModel, diags *diag.Diagnostics) bool {
+	alias, err := r.client.getItem(ctx, "firewall/alias/getItem/"+data.ID.ValueString(), "alias")
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read alias: %s", err))
+		return false
+	}
+	if len(alias) == 0 {
+		return false
+	}
+
+	data.Name = types.StringValue(stringField(alias, "name"))
+	data.Type = types.StringValue(selectedOption(alias["type"]))
+	data.Description = stringFromAPI(data.Description, stringField(alias, "description"))
+
+	// Enabled defaults to true, keep it null when unset
+	if enabled := boolField(alias, "enabled"); !enabled || !data.Enabled.IsNull() {
+		data.Enabled = types.BoolValue(enabled)
+	}
+
+	r.readEntries(ctx, data, aliasEntriesFromAPI(alias), diags)
+
+	protos, d := setFromAPI(ctx, data.Proto, selectedOptions(alias["proto"]))
+	diags.Append(d...)
+	data.Proto = protos
+
+	if freq, err := strconv.ParseFloat(stringField(alias, "updatefreq"), 64); err == nil && freq != 0 {
+		data.UpdateFreq = types.Float64Value(freq)
+	} else if !data.UpdateFreq.IsNull() {
+		data.UpdateFreq = types.Float64Value(0)
+	}
+
+	data.Counters = boolFromAPI(data.Counters, boolField(alias, "counters"))
+	data.Interface = stringFromAPI(data.Interface, selectedOption(alias["interface"]))
+	data.PathExpression = stringFromAPI(data.PathExpression, stringField(alias, "path_expression"))
+	data.AuthType = stringFromAPI(data.AuthType, selectedOption(alias["authtype"]))
+	data.Username = stringFromAPI(data.Username, stringField(alias, "username"))
+
+	data.Categories = r.client.categoriesFromAPI(ctx, data.Categories, alias["categories"], diags)
+
+	return true
+}
+
+// aliasEntry is a content entry as exchanged with the API.
+type aliasEntry struct {
+	address     string
+	description string
+}
+
+// configuredEntries returns the entries of content or entries, whichever is set.
+func (r *FirewallAliasResource) configuredEntries(ctx context.Context, data *FirewallAliasResourceModel, diags *diag.Diagnostics) []aliasEntry {
+	var entries []aliasEntry
+
+	if !data.Entries.IsNull() && !data.Entries.IsUnknown() {
+		var models []aliasEntryModel
+		diags.Append(data.Entries.ElementsAs(ctx, &models, false)...)
+		for _, m := range models {
+			entries = append(entries, aliasEntry{address: m.Address.ValueString(), description: m.Description.ValueString()})
+		}
+	} else if !data.Content.IsNull() && !data.Content.IsUnknown() {
+		var content []string
+		diags.Append(data.Content.ElementsAs(ctx, &content, false)...)
+		for _, address := range content {
+			entries = append(entries, aliasEntry{address: address})
+		}
+	}
+
+	return entries
+}
+
+// readEntries stores the API entries in content or entries. Entries equal to a
+// configured one after normalization keep the configured spelling.
+func (r *FirewallAliasResource) readEntries(ctx context.Context, data *FirewallAliasResourceModel, apiEntries []aliasEntry, diags *diag.Diagnostics) {
+	aliasType := data.Type.ValueString()
+
+	configured := map[string]string{}
+	for _, entry := range r.configuredEntries(ctx, data, diags) {
+		configured[normalizeAliasEntry(aliasType, entry.address)] = entry.address
+	}
+	for i, entry := range apiEntries {
+		if address, ok := configured[normalizeAliasEntry(aliasType, entry.address)]; ok {
+			apiEntries[i].address = address
+		}
+	}
+
+	if data.Content.IsNull() && data.Entries.IsNull() {
+		// Content isn't managed, e.g. entries come from opnsense_firewall_alias_entry
+		return
+	}
+
+	if !data.Entries.IsNull() {
+		models := make([]aliasEntryModel, 0, len(apiEntries))
+		for _, entry := range apiEntries {
+			description := types.StringNull()
+			if entry.description != "" {
+				description = types.StringValue(entry.description)
+			}
+			models = append(models, aliasEntryModel{Address: types.StringValue(entry.address), Description: description})
+		}
+		set, d := types.SetValueFrom(ctx, types.ObjectType{AttrTypes: aliasEntryAttrTypes}, models)
+		diags.Append(d...)
+		data.Entries = set
+		data.Content = types.SetNull(types.StringType)
+		return
+	}
+
+	addresses := make([]string, 0, len(apiEntries))
+	for _, entry := range apiEntries {
+		addresses = append(addresses, entry.address)
+	}
+	content, d := types.SetValueFrom(ctx, types.StringType, addresses)
+	diags.Append(d...)
+	data.Content = content
+}
+
+// aliasEntriesFromAPI returns the entries of an alias. getItem renders content
+// as an option list keyed by entry, possibly carrying its description; older
+// versions return a newline-separated string with descriptions alongside.
+func aliasEntriesFromAPI(alias map[string]interface{}) []aliasEntry {
+	var entries []aliasEntry
+
+	switch content := alias["content"].(type) {
+	case string:
+		descriptions := strings.Split(stringField(alias, "descriptions"), "\n")
+		for i, address := range strings.Split(content, "\n") {
+			if address = strings.TrimSpace(address); address == "" {
+				continue
+			}
+			entry := aliasEntry{address: address}
+			if i < len(descriptions) {
+				entry.description = strings.TrimSpace(descriptions[i])
+			}
+			entries = append(entries, entry)
+		}
+	case map[string]interface{}:
+		for _, address := range selectedOptions(content) {
+			entry := aliasEntry{address: address}
+			if opt, ok := content[address].(map[string]interface{}); ok {
+				entry.description = stringField(opt, "description")
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// applyAliases reloads aliases after configuration changes. Like applyNat, a
+// failed reconfigure is logged and picked up by the next one.
+func applyAliases(ctx context.Context, client *Client) {
+	if err := client.post(ctx, "firewall/alias/reconfigure"); err != nil {
+		tflog.Warn(ctx, "Failed to reconfigure aliases", map[string]any{"error": err.Error()})
 	}
-	return keys
 }
@@ -0,0 +1,602 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ resource.Resource = &FirewallAliasBundleResource{}
+var _ resource.ResourceWithImportState = &FirewallAliasBundleResource{}
+var _ resource.ResourceWithModifyPlan = &FirewallAliasBundleResource{}
+var _ resource.ResourceWithValidateConfig = &FirewallAliasBundleResource{}
+
+func NewFirewallAliasBundleResource() resource.Resource {
+	return &FirewallAliasBundleResource{}
+}
+
+// FirewallAliasBundleResource manages many aliases at once through the alias
+// import/export endpoints: changed aliases are imported in one request and
+// reconfigured once, instead of one addItem/setItem and reconfigure each.
+type FirewallAliasBundleResource struct {
+	client *Client
+}
+
+type FirewallAliasBundleResourceModel struct {
+	ID      types.String `tfsdk:"id"`
+	Aliases types.Map    `tfsdk:"aliases"`
+	UUIDs   types.Map    `tfsdk:"uuids"`
+}
+
+// aliasBundleItemModel is one alias of a bundle, keyed by name.
+type aliasBundleItemModel struct {
+	Type        types.String  `tfsdk:"type"`
+	Content     types.Set     `tfsdk:"content"`
+	Description types.String  `tfsdk:"description"`
+	Enabled     types.Bool    `tfsdk:"enabled"`
+	Proto       types.Set     `tfsdk:"proto"`
+	UpdateFreq  types.Float64 `tfsdk:"update_freq"`
+	Counters    types.Bool    `tfsdk:"counters"`
+}
+
+var aliasBundleItemAttrTypes = map[string]attr.Type{
+	"type":        types.StringType,
+	"content":     types.SetType{ElemType: types.StringType},
+	"description": types.StringType,
+	"enabled":     types.BoolType,
+	"proto":       types.SetType{ElemType: types.StringType},
+	"update_freq": types.Float64Type,
+	"counters":    types.BoolType,
+}
+
+func (r *FirewallAliasBundleResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_firewall_alias_bundle"
+}
+
+func (r *FirewallAliasBundleResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a set of OPNsense firewall aliases in bulk via `/api/firewall/alias/import` and `/export`. " +
+			"Only added or changed aliases are imported, followed by a single reconfigure. " +
+			"Don't manage the same alias with `opnsense_firewall_alias` as well.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Bundle identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"aliases": schema.MapNestedAttribute{
+				MarkdownDescription: "Aliases keyed by name",
+				Required:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							MarkdownDescription: "Type of alias: " + strings.Join(aliasTypes, ", "),
+							Required:            true,
+							Validators: []validator.String{
+								stringOneOf(aliasTypes...),
+							},
+						},
+						"content": schema.SetAttribute{
+							MarkdownDescription: "Set of alias entries, validated against `type`",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "Description of the alias",
+							Optional:            true,
+						},
+						"enabled": schema.BoolAttribute{
+							MarkdownDescription: "Whether the alias is enabled",
+							Optional:            true,
+						},
+						"proto": schema.SetAttribute{
+							MarkdownDescription: "Address families for `geoip` and `asn` aliases: 'IPv4', 'IPv6'",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"update_freq": schema.Float64Attribute{
+							MarkdownDescription: "Refresh frequency in days for `urltable` and `urljson` aliases",
+							Optional:            true,
+						},
+						"counters": schema.BoolAttribute{
+							MarkdownDescription: "Collect pf table statistics for this alias",
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"uuids": schema.MapAttribute{
+				MarkdownDescription: "UUID of each alias, keyed by name",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+		},
+	}
+}
+
+func (r *FirewallAliasBundleResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+// ValidateConfig applies the opnsense_firewall_alias name and content checks
+// to every alias of the bundle.
+func (r *FirewallAliasBundleResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var aliasMap types.Map
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("aliases"), &aliasMap)...)
+	if resp.Diagnostics.HasError() || aliasMap.IsUnknown() || aliasMap.IsNull() {
+		return
+	}
+
+	var aliases map[string]aliasBundleItemModel
+	resp.Diagnostics.Append(aliasMap.ElementsAs(ctx, &aliases, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for name, alias := range aliases {
+		p := path.Root("aliases").AtMapKey(name)
+		if !aliasNamePattern.MatchString(name) {
+			resp.Diagnostics.AddAttributeError(p, "Invalid Alias Name",
+				fmt.Sprintf("Alias name %q may only contain letters, digits and underscores and be at most 31 characters long.", name))
+		}
+		if alias.Type.IsUnknown() || alias.Type.IsNull() || alias.Content.IsUnknown() || alias.Content.IsNull() {
+			continue
+		}
+
+		var entries []types.String
+		resp.Diagnostics.Append(alias.Content.ElementsAs(ctx, &entries, false)...)
+		for _, entry := range entries {
+			if entry.IsUnknown() {
+				continue
+			}
+			if problem := validateAliasEntry(alias.Type.ValueString(), entry.ValueString()); problem != "" {
+				resp.Diagnostics.AddAttributeError(p.AtName("content").AtSetValue(entry), "Invalid Alias Entry",
+					fmt.Sprintf("Entry %q is not valid for a %s alias: %s.", entry.ValueString(), alias.Type.ValueString(), problem))
+			}
+		}
+	}
+}
+
+// ModifyPlan registers the planned alias names for rule reference checks and
+// keeps uuids known while no alias is added or removed.
+func (r *FirewallAliasBundleResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var plan FirewallAliasBundleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || plan.Aliases.IsUnknown() {
+		return
+	}
+
+	for name := range plan.Aliases.Elements() {
+		r.client.registerPlanned(refKindAlias, name)
+	}
+
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var state FirewallAliasBundleResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuids := types.MapUnknown(types.StringType)
+	if reflect.DeepEqual(sortedKeys(plan.Aliases.Elements()), sortedKeys(state.UUIDs.Elements())) {
+		uuids = state.UUIDs
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("uuids"), uuids)...)
+}
+
+func (r *FirewallAliasBundleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data FirewallAliasBundleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = types.StringValue("aliases")
+	data.UUIDs = types.MapNull(types.StringType)
+	r.apply(ctx, &data, nil, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FirewallAliasBundleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data FirewallAliasBundleResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FirewallAliasBundleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state FirewallAliasBundleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.UUIDs = state.UUIDs
+	r.apply(ctx, &data, &state, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FirewallAliasBundleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data FirewallAliasBundleResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuids := map[string]string{}
+	resp.Diagnostics.Append(data.UUIDs.ElementsAs(ctx, &uuids, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, name := range sortedKeys(uuids) {
+		if err := r.client.post(ctx, "firewall/alias/delItem/"+uuids[name]); err != nil && !isNotFound(err) {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete alias %s: %s", name, err))
+		}
+	}
+
+	applyAliases(ctx, r.client)
+	r.client.invalidateObjects(refKindAlias)
+}
+
+func (r *FirewallAliasBundleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Import ID is a comma-separated list of alias names to adopt
+	names := map[string]aliasBundleItemModel{}
+	for _, name := range strings.Split(req.ID, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names[name] = aliasBundleItemModel{
+				Type:        types.StringNull(),
+				Content:     types.SetNull(types.StringType),
+				Description: types.StringNull(),
+				Enabled:     types.BoolNull(),
+				Proto:       types.SetNull(types.StringType),
+				UpdateFreq:  types.Float64Null(),
+				Counters:    types.BoolNull(),
+			}
+		}
+	}
+	if len(names) == 0 {
+		resp.Diagnostics.AddError("Invalid Import ID", "Expected a comma-separated list of alias names")
+		return
+	}
+
+	// refresh only reads back content that is set, so seed it from the
+	// firewall; otherwise the first plan would import every alias again
+	exported, err := r.client.exportAliases(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to export aliases: %s", err))
+		return
+	}
+	for name, item := range names {
+		found, ok := exported[name]
+		if !ok {
+			resp.Diagnostics.AddError("Alias Not Found", fmt.Sprintf("No alias named %q exists on the firewall.", name))
+			continue
+		}
+
+		var entries []string
+		for _, entry := range aliasEntriesFromAPI(found) {
+			entries = append(entries, entry.address)
+		}
+		if len(entries) > 0 {
+			content, diags := types.SetValueFrom(ctx, types.StringType, entries)
+			resp.Diagnostics.Append(diags...)
+			item.Content = content
+			names[name] = item
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	aliases, diags := types.MapValueFrom(ctx, types.ObjectType{AttrTypes: aliasBundleItemAttrTypes}, names)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), "aliases")...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("aliases"), aliases)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("uuids"), types.MapNull(types.StringType))...)
+}
+
+// apply imports every alias that is new or differs from prior (nil on create),
+// deletes the aliases dropped from the bundle and reconfigures once. New names
+// that already exist on the firewall are rejected rather than overwritten.
+func (r *FirewallAliasBundleResource) apply(ctx context.Context, data, prior *FirewallAliasBundleResourceModel, diags *diag.Diagnostics) {
+	planned := map[string]aliasBundleItemModel{}
+	diags.Append(data.Aliases.ElementsAs(ctx, &planned, false)...)
+
+	previous := map[string]aliasBundleItemModel{}
+	uuids := map[string]string{}
+	if prior != nil {
+		diags.Append(prior.Aliases.ElementsAs(ctx, &previous, false)...)
+		if !prior.UUIDs.IsNull() {
+			diags.Append(prior.UUIDs.ElementsAs(ctx, &uuids, false)...)
+		}
+	}
+	if diags.HasError() {
+		return
+	}
+
+	changed := map[string]interface{}{}
+	for name, alias := range planned {
+		payload := r.aliasPayload(ctx, name, alias, diags)
+		if old, ok := previous[name]; ok && reflect.DeepEqual(payload, r.aliasPayload(ctx, name, old, diags)) {
+			continue
+		}
+		// Import matches aliases by name, the key is only used to group them
+		changed[name] = payload
+	}
+
+	var removed []string
+	for name := range previous {
+		if _, ok := planned[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+
+	if len(changed) == 0 && len(removed) == 0 {
+		return
+	}
+
+	// Import matches by name, so a new name would take over an existing alias
+	var added []string
+	for name := range changed {
+		if _, ok := previous[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	if len(added) > 0 {
+		exported, err := r.client.exportAliases(ctx)
+		if err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to export aliases: %s", err))
+			return
+		}
+		var existing []string
+		for _, name := range added {
+			if _, ok := exported[name]; ok {
+				existing = append(existing, name)
+			}
+		}
+		if len(existing) > 0 {
+			sort.Strings(existing)
+			diags.AddError("Aliases Already Exist",
+				fmt.Sprintf("Aliases %s already exist on the firewall and would be overwritten. "+
+					"Adopt them with `terraform import` (ID: comma-separated alias names) or remove them from the bundle.",
+					strings.Join(existing, ", ")))
+			return
+		}
+	}
+
+	if len(changed) > 0 {
+		tflog.Debug(ctx, "Importing aliases", map[string]any{"aliases": sortedKeys(changed)})
+		if err := r.client.importAliases(ctx, changed); err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to import aliases: %s", err))
+			return
+		}
+	}
+
+	for _, name := range removed {
+		uuid, ok := uuids[name]
+		if !ok {
+			continue
+		}
+		if err := r.client.post(ctx, "firewall/alias/delItem/"+uuid); err != nil && !isNotFound(err) {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to delete alias %s: %s", name, err))
+			return
+		}
+	}
+
+	applyAliases(ctx, r.client)
+	r.client.invalidateObjects(refKindAlias)
+}
+
+func (r *FirewallAliasBundleResource) aliasPayload(ctx context.Context, name string, alias aliasBundleItemModel, diags *diag.Diagnostics) map[string]interface{} {
+	var protos []string
+	if !alias.Proto.IsNull() && !alias.Proto.IsUnknown() {
+		diags.Append(alias.Proto.ElementsAs(ctx, &protos, false)...)
+	}
+
+	payload := map[string]interface{}{
+		"name":        name,
+		"type":        alias.Type.ValueString(),
+		"description": alias.Description.ValueString(),
+		"enabled":     boolString(alias.Enabled.IsNull() || alias.Enabled.ValueBool()),
+		"proto":       joinSorted(protos),
+		"counters":    boolString(alias.Counters.ValueBool()),
+		"updatefreq":  "",
+	}
+
+	if !alias.UpdateFreq.IsNull() && !alias.UpdateFreq.IsUnknown() {
+		payload["updatefreq"] = strconv.FormatFloat(alias.UpdateFreq.ValueFloat64(), 'f', -1, 64)
+	}
+
+	if !alias.Content.IsNull() && !alias.Content.IsUnknown() {
+		var content []string
+		diags.Append(alias.Content.ElementsAs(ctx, &content, false)...)
+		sort.Strings(content)
+		payload["content"] = strings.Join(content, "\n")
+	}
+
+	return payload
+}
+
+// refresh reads every alias of the bundle from one export. Aliases missing on
+// the firewall are dropped, so the next plan re-adds them.
+func (r *FirewallAliasBundleResource) refresh(ctx context.Context, data *FirewallAliasBundleResourceModel, diags *diag.Diagnostics) {
+	exported, err := r.client.exportAliases(ctx)
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to export aliases: %s", err))
+		return
+	}
+
+	current := map[string]aliasBundleItemModel{}
+	diags.Append(data.Aliases.ElementsAs(ctx, &current, false)...)
+	if diags.HasError() {
+		return
+	}
+
+	items := make(map[string]aliasBundleItemModel, len(current))
+	uuids := make(map[string]string, len(current))
+	for name, item := range current {
+		found, ok := exported[name]
+		if !ok {
+			tflog.Warn(ctx, "Alias of bundle no longer exists", map[string]any{"name": name})
+			continue
+		}
+		uuids[name] = stringField(found, "uuid")
+
+		item.Type = types.StringValue(stringField(found, "type"))
+		item.Description = stringFromAPI(item.Description, stringField(found, "description"))
+		if enabled := boolField(found, "enabled"); !enabled || !item.Enabled.IsNull() {
+			item.Enabled = types.BoolValue(enabled)
+		}
+		item.Counters = boolFromAPI(item.Counters, boolField(found, "counters"))
+
+		if !item.Content.IsNull() {
+			var configured []string
+			diags.Append(item.Content.ElementsAs(ctx, &configured, false)...)
+
+			var entries []string
+			for _, entry := range aliasEntriesFromAPI(found) {
+				entries = append(entries, entry.address)
+			}
+			content, d := types.SetValueFrom(ctx, types.StringType, keepConfiguredSpelling(item.Type.ValueString(), configured, entries))
+			diags.Append(d...)
+			item.Content = content
+		}
+
+		protos, d := setFromAPI(ctx, item.Proto, selectedOptions(found["proto"]))
+		diags.Append(d...)
+		item.Proto = protos
+
+		if freq, err := strconv.ParseFloat(stringField(found, "updatefreq"), 64); err == nil && freq != 0 {
+			item.UpdateFreq = types.Float64Value(freq)
+		} else if !item.UpdateFreq.IsNull() {
+			item.UpdateFreq = types.Float64Value(0)
+		}
+
+		items[name] = item
+	}
+
+	aliases, d := types.MapValueFrom(ctx, types.ObjectType{AttrTypes: aliasBundleItemAttrTypes}, items)
+	diags.Append(d...)
+	uuidMap, d := types.MapValueFrom(ctx, types.StringType, uuids)
+	diags.Append(d...)
+
+	data.Aliases = aliases
+	data.UUIDs = uuidMap
+}
+
+// importAliases posts aliases in the export format. Existing aliases are
+// matched by name and updated, the others are added.
+func (c *Client) importAliases(ctx context.Context, aliases map[string]interface{}) error {
+	result, err := c.doJSON(ctx, "POST", "firewall/alias/import", map[string]interface{}{
+		"data": map[string]interface{}{
+			"aliases": map[string]interface{}{
+				"alias": aliases,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if status, _ := result["status"].(string); status == "failed" {
+		if _, ok := result["validations"]; ok {
+			result["result"] = "failed"
+			return resultError(result)
+		}
+		return fmt.Errorf("import failed: %v", result)
+	}
+	return nil
+}
+
+// exportAliases returns every alias keyed by name, with its UUID under "uuid".
+func (c *Client) exportAliases(ctx context.Context) (map[string]map[string]interface{}, error) {
+	result, err := c.doJSON(ctx, "GET", "firewall/alias/export", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := map[string]map[string]interface{}{}
+	container, _ := result["aliases"].(map[string]interface{})
+	items, _ := container["alias"].(map[string]interface{})
+	for uuid, raw := range items {
+		alias, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		alias["uuid"] = uuid
+		aliases[stringField(alias, "name")] = alias
+	}
+	return aliases, nil
+}
+
+// sortedKeys returns the keys of m in a stable order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,229 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &FirewallAliasEntryResource{}
+var _ resource.ResourceWithImportState = &FirewallAliasEntryResource{}
+
+func NewFirewallAliasEntryResource() resource.Resource {
+	return &FirewallAliasEntryResource{}
+}
+
+// FirewallAliasEntryResource manages a single address of an alias through
+// alias_util, leaving the other entries alone. Several configurations can
+// contribute entries to the same alias this way.
+type FirewallAliasEntryResource struct {
+	client *Client
+}
+
+type FirewallAliasEntryResourceModel struct {
+	ID      types.String `tfsdk:"id"`
+	Alias   types.String `tfsdk:"alias"`
+	Address types.String `tfsdk:"address"`
+}
+
+func (r *FirewallAliasEntryResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_firewall_alias_entry"
+}
+
+func (r *FirewallAliasEntryResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a single entry of an OPNsense firewall alias via `/api/firewall/alias_util`. " +
+			"The alias itself is not rewritten, so entries can be contributed from several configurations. " +
+			"Leave `content` unset on an `opnsense_firewall_alias` fed this way.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Entry identifier (`<alias>/<address>`)",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"alias": schema.StringAttribute{
+				MarkdownDescription: "Name of the alias (host, network or external type)",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"address": schema.StringAttribute{
+				MarkdownDescription: "Address, network or hostname to add (e.g., '198.51.100.7', '203.0.113.0/24' or 'backup.example.com')",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+	}
+}
+
+func (r *FirewallAliasEntryResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *FirewallAliasEntryResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data FirewallAliasEntryResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.aliasUtil(ctx, "add", data.Alias.ValueString(), data.Address.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to add %s to alias %s: %s", data.Address.ValueString(), data.Alias.ValueString(), err))
+		return
+	}
+
+	data.ID = types.StringValue(data.Alias.ValueString() + "/" + data.Address.ValueString())
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FirewallAliasEntryResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data FirewallAliasEntryResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	addresses, err := r.client.aliasEntryCandidates(ctx, data.Alias.ValueString(), data.Address.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list alias %s: %s", data.Alias.ValueString(), err))
+		return
+	}
+
+	// pf reports single addresses without prefix length
+	want := normalizeAliasEntry("network", data.Address.ValueString())
+	for _, address := range addresses {
+		if normalizeAliasEntry("network", address) == want {
+			resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+			return
+		}
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+func (r *FirewallAliasEntryResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Every attribute requires replacement
+	var data FirewallAliasEntryResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FirewallAliasEntryResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data FirewallAliasEntryResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.aliasUtil(ctx, "delete", data.Alias.ValueString(), data.Address.ValueString()); err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to remove %s from alias %s: %s", data.Address.ValueString(), data.Alias.ValueString(), err))
+	}
+}
+
+func (r *FirewallAliasEntryResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Alias names can't contain "/", addresses can (CIDR)
+	alias, address, ok := strings.Cut(req.ID, "/")
+	if !ok || alias == "" || address == "" {
+		resp.Diagnostics.AddError("Invalid Import ID", "Expected <alias>/<address>, e.g. BLOCKLIST/198.51.100.7")
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("alias"), alias)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("address"), address)...)
+}
+
+// aliasUtil adds or deletes a single address of an alias. alias_util answers
+// with {"status": "done"} rather than the usual result field.
+func (c *Client) aliasUtil(ctx context.Context, action, alias, address string) error {
+	result, err := c.doJSON(ctx, "POST", "firewall/alias_util/"+action+"/"+alias, map[string]interface{}{
+		"address": address,
+	})
+	if err != nil {
+		return err
+	}
+
+	if status, ok := result["status"].(string); ok && status != "done" {
+		return fmt.Errorf("alias_util %s returned status %q", action, status)
+	}
+	return resultError(result)
+}
+
+// aliasTable returns the addresses currently loaded in the pf table of alias.
+func (c *Client) aliasTable(ctx context.Context, alias string) ([]string, error) {
+	rows, err := c.searchItems(ctx, "firewall/alias_util/list/"+alias)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if ip := stringField(row, "ip"); ip != "" {
+			addresses = append(addresses, ip)
+		}
+	}
+	return addresses, nil
+}
+
+// aliasEntryCandidates returns what address has to be found in to still be
+// part of alias. Hostnames are resolved before they reach the pf table, so
+// they are looked up in the configured content of the alias instead.
+func (c *Client) aliasEntryCandidates(ctx context.Context, alias, address string) ([]string, error) {
+	if _, err := netip.ParsePrefix(address); err == nil {
+		return c.aliasTable(ctx, alias)
+	}
+	if _, err := netip.ParseAddr(address); err == nil {
+		return c.aliasTable(ctx, alias)
+	}
+
+	aliases, err := c.exportAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	item, ok := aliases[alias]
+	if !ok {
+		return nil, nil
+	}
+
+	var addresses []string
+	for _, entry := range aliasEntriesFromAPI(item) {
+		addresses = append(addresses, entry.address)
+	}
+	return addresses, nil
+}
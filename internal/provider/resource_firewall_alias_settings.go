@@ -0,0 +1,165 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ resource.Resource = &FirewallAliasSettingsResource{}
+var _ resource.ResourceWithImportState = &FirewallAliasSettingsResource{}
+
+func NewFirewallAliasSettingsResource() resource.Resource {
+	return &FirewallAliasSettingsResource{}
+}
+
+// FirewallAliasSettingsResource manages the global settings of the alias
+// model. There is only one instance per firewall. The GeoIP URL is the only
+// global firewall/alias/get returns; the resolver interval and URL certificate
+// check live in the legacy advanced settings, which have no API.
+type FirewallAliasSettingsResource struct {
+	client *Client
+}
+
+type FirewallAliasSettingsResourceModel struct {
+	ID       types.String `tfsdk:"id"`
+	GeoIPURL types.String `tfsdk:"geoip_url"`
+}
+
+func (r *FirewallAliasSettingsResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_firewall_alias_settings"
+}
+
+func (r *FirewallAliasSettingsResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the global OPNsense alias settings. The GeoIP database URL is the only global setting " +
+			"`/api/firewall/alias/get` exposes; the alias resolve interval and URL certificate check are legacy " +
+			"advanced settings without an API. Singleton: declare it once per firewall. Destroying it leaves the settings in place.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Always `alias_settings`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"geoip_url": schema.StringAttribute{
+				MarkdownDescription: "URL of the GeoIP database used by `geoip` aliases, e.g. a MaxMind GeoLite2 Country CSV download URL including the license key",
+				Required:            true,
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (r *FirewallAliasSettingsResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *FirewallAliasSettingsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data FirewallAliasSettingsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = types.StringValue("alias_settings")
+	r.apply(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FirewallAliasSettingsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data FirewallAliasSettingsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FirewallAliasSettingsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data FirewallAliasSettingsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.apply(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FirewallAliasSettingsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Global settings can't be removed, only the ownership ends
+	tflog.Trace(ctx, "removed firewall alias settings from state")
+}
+
+func (r *FirewallAliasSettingsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// apply saves the settings and reconfigures aliases, which downloads the GeoIP
+// database again and reloads geoip aliases.
+func (r *FirewallAliasSettingsResource) apply(ctx context.Context, data *FirewallAliasSettingsResourceModel, diags *diag.Diagnostics) {
+	// Only the posted nodes are changed, the aliases themselves stay untouched
+	payload := map[string]interface{}{
+		"alias": map[string]interface{}{
+			"geoip": map[string]interface{}{
+				"url": data.GeoIPURL.ValueString(),
+			},
+		},
+	}
+
+	if err := r.client.setItem(ctx, "firewall/alias/set", payload); err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to update alias settings: %s", err))
+		return
+	}
+
+	applyAliases(ctx, r.client)
+}
+
+func (r *FirewallAliasSettingsResource) refresh(ctx context.Context, data *FirewallAliasSettingsResourceModel, diags *diag.Diagnostics) {
+	settings, err := r.client.getItem(ctx, "firewall/alias/get", "alias")
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read alias settings: %s", err))
+		return
+	}
+
+	geoip, _ := settings["geoip"].(map[string]interface{})
+	data.GeoIPURL = types.StringValue(stringField(geoip, "url"))
+}
@@ -18,6 +18,7 @@ import (
 
 var _ resource.Resource = &FirewallCategoryResource{}
 var _ resource.ResourceWithImportState = &FirewallCategoryResource{}
+var _ resource.ResourceWithModifyPlan = &FirewallCategoryResource{}
 
 func NewFirewallCategoryResource() resource.Resource {
 	return &FirewallCategoryResource{}
@@ -137,6 +138,8 @@ func (r *FirewallCategoryResource) Create(ctx context.Context, req resource.Crea
 		return
 	}
 
+	r.client.invalidateObjects(refKindCategory)
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -205,6 +208,8 @@ func (r *FirewallCategoryResource) Update(ctx context.Context, req resource.Upda
 	}
 	defer httpResp.Body.Close()
 
+	r.client.invalidateObjects(refKindCategory)
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -225,6 +230,23 @@ func (r *FirewallCategoryResource) Delete(ctx context.Context, req resource.Dele
 		return
 	}
 	defer httpResp.Body.Close()
+
+	r.client.invalidateObjects(refKindCategory)
+}
+
+// ModifyPlan registers the planned name so firewall rules referencing it
+// pass their plan-time reference checks before it exists.
+func (r *FirewallCategoryResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var name types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("name"), &name)...)
+	if resp.Diagnostics.HasError() || name.IsUnknown() || name.IsNull() {
+		return
+	}
+	r.client.registerPlanned(refKindCategory, name.ValueString())
 }
 
 func (r *FirewallCategoryResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
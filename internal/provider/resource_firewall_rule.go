@@ -8,11 +8,14 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -20,6 +23,8 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &FirewallRuleResource{}
 var _ resource.ResourceWithImportState = &FirewallRuleResource{}
+var _ resource.ResourceWithValidateConfig = &FirewallRuleResource{}
+var _ resource.ResourceWithModifyPlan = &FirewallRuleResource{}
 
 func NewFirewallRuleResource() resource.Resource {
 	return &FirewallRuleResource{}
@@ -49,10 +54,41 @@ type FirewallRuleResourceModel struct {
 	Log         types.Bool   `tfsdk:"log"`
 	Quick       types.Bool   `tfsdk:"quick"`
 	Invert      types.Bool   `tfsdk:"invert"`
+	// Multi-interface (floating) rules
+	Interfaces   types.Set  `tfsdk:"interfaces"`
+	InterfaceNot types.Bool `tfsdk:"interface_not"`
 	// Deprecated fields for backward compatibility - kept to avoid state errors
 	SourceNot      types.Bool `tfsdk:"source_not"`
 	DestinationNot types.Bool `tfsdk:"destination_not"`
 	Categories     types.List `tfsdk:"categories"`
+	// Advanced options
+	StateType       types.String `tfsdk:"state_type"`
+	StatePolicy     types.String `tfsdk:"state_policy"`
+	StateTimeout    types.Int64  `tfsdk:"state_timeout"`
+	MaxStates       types.Int64  `tfsdk:"max_states"`
+	MaxSrcNodes     types.Int64  `tfsdk:"max_src_nodes"`
+	MaxSrcStates    types.Int64  `tfsdk:"max_src_states"`
+	MaxSrcConn      types.Int64  `tfsdk:"max_src_conn"`
+	MaxSrcConnRate  types.Int64  `tfsdk:"max_src_conn_rate"`
+	MaxSrcConnRates types.Int64  `tfsdk:"max_src_conn_rates"`
+	OverloadTable   types.String `tfsdk:"overload_table"`
+	TCPFlags        types.Set    `tfsdk:"tcp_flags"`
+	TCPFlagsOutOf   types.Set    `tfsdk:"tcp_flags_out_of"`
+	TCPFlagsAny     types.Bool   `tfsdk:"tcp_flags_any"`
+	ICMPTypes       types.Set    `tfsdk:"icmp_types"`
+	ICMP6Types      types.Set    `tfsdk:"icmp6_types"`
+	Tag             types.String `tfsdk:"tag"`
+	Tagged          types.String `tfsdk:"tagged"`
+	MatchPriority   types.String `tfsdk:"match_priority"`
+	SetPriority     types.String `tfsdk:"set_priority"`
+	SetPriorityLow  types.String `tfsdk:"set_priority_low"`
+	DSCP            types.String `tfsdk:"dscp"`
+	ReplyTo         types.String `tfsdk:"reply_to"`
+	DisableReplyTo  types.Bool   `tfsdk:"disable_reply_to"`
+	AllowOptions    types.Bool   `tfsdk:"allow_options"`
+	NoXMLRPCSync    types.Bool   `tfsdk:"no_xmlrpc_sync"`
+	NoPfsync        types.Bool   `tfsdk:"no_pfsync"`
+	Schedule        types.String `tfsdk:"schedule"`
 }
 
 func (r *FirewallRuleResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -76,21 +112,39 @@ func (r *FirewallRuleResource) Schema(ctx context.Context, req resource.SchemaRe
 				Required:            true,
 			},
 			"sequence": schema.Int64Attribute{
-				MarkdownDescription: "Rule sequence/sort order (e.g., 800). Lower numbers are processed first.",
+				MarkdownDescription: "Rule sequence/sort order (e.g., 800). Lower numbers are processed first. Leave unset when the rule is ordered by `opnsense_firewall_rule_order`.",
 				Optional:            true,
 				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
 			},
 			"interface": schema.StringAttribute{
-				MarkdownDescription: "Interface name (e.g., 'wan', 'lan', 'opt1')",
+				MarkdownDescription: "Interface name (e.g., 'wan', 'lan', 'opt1'). Conflicts with `interfaces`",
+				Optional:            true,
+			},
+			"interfaces": schema.SetAttribute{
+				MarkdownDescription: "Interface names or interface groups for a multi-interface (floating) rule. Conflicts with `interface`",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"interface_not": schema.BoolAttribute{
+				MarkdownDescription: "Match every interface except the selected ones",
 				Optional:            true,
 			},
 			"direction": schema.StringAttribute{
-				MarkdownDescription: "Direction of traffic ('in' or 'out'). Default is 'in'",
+				MarkdownDescription: "Direction of traffic ('in', 'out', or 'any' for floating rules). Default is 'in'",
 				Optional:            true,
+				Validators: []validator.String{
+					stringOneOf("in", "out", "any"),
+				},
 			},
 			"ip_protocol": schema.StringAttribute{
-				MarkdownDescription: "IP protocol version ('inet' for IPv4, 'inet6' for IPv6). Default is 'inet'",
+				MarkdownDescription: "IP protocol version ('inet' for IPv4, 'inet6' for IPv6, 'inet46' for both). Default is 'inet'",
 				Optional:            true,
+				Validators: []validator.String{
+					stringOneOf("inet", "inet6", "inet46"),
+				},
 			},
 			"protocol": schema.StringAttribute{
 				MarkdownDescription: "Protocol (tcp, udp, icmp, any, etc.)",
@@ -129,7 +183,7 @@ func (r *FirewallRuleResource) Schema(ctx context.Context, req resource.SchemaRe
 				Optional:            true,
 			},
 			"quick": schema.BoolAttribute{
-				MarkdownDescription: "Apply action immediately on match",
+				MarkdownDescription: "Apply action immediately on match (default: true). Floating rules without quick are last-match: later matching rules decide",
 				Optional:            true,
 			},
 			"invert": schema.BoolAttribute{
@@ -145,10 +199,129 @@ func (r *FirewallRuleResource) Schema(ctx context.Context, req resource.SchemaRe
 				Optional:            true,
 			},
 			"categories": schema.ListAttribute{
-				MarkdownDescription: "List of category UUIDs for organizing rules",
+				MarkdownDescription: "List of category names or UUIDs for organizing rules. Names that don't exist yet are created as automatic categories",
 				Optional:            true,
 				ElementType:         types.StringType,
 			},
+			// Advanced options
+			"state_type": schema.StringAttribute{
+				MarkdownDescription: "State tracking ('keep', 'sloppy', 'modulate', 'synproxy', 'none'). Default is 'keep'",
+				Optional:            true,
+				Validators: []validator.String{
+					stringOneOf("keep", "sloppy", "modulate", "synproxy", "none"),
+				},
+			},
+			"state_policy": schema.StringAttribute{
+				MarkdownDescription: "State policy ('if-bound' or 'floating'). Default follows the global setting",
+				Optional:            true,
+				Validators: []validator.String{
+					stringOneOf("if-bound", "floating"),
+				},
+			},
+			"state_timeout": schema.Int64Attribute{
+				MarkdownDescription: "State timeout in seconds",
+				Optional:            true,
+			},
+			"max_states": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of states this rule can create",
+				Optional:            true,
+			},
+			"max_src_nodes": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of unique source hosts",
+				Optional:            true,
+			},
+			"max_src_states": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of states per source host",
+				Optional:            true,
+			},
+			"max_src_conn": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of established TCP connections per source host",
+				Optional:            true,
+			},
+			"max_src_conn_rate": schema.Int64Attribute{
+				MarkdownDescription: "Maximum new connections per source host within `max_src_conn_rates` seconds",
+				Optional:            true,
+			},
+			"max_src_conn_rates": schema.Int64Attribute{
+				MarkdownDescription: "Interval in seconds for `max_src_conn_rate`",
+				Optional:            true,
+			},
+			"overload_table": schema.StringAttribute{
+				MarkdownDescription: "Alias (table) receiving source hosts exceeding the connection limits",
+				Optional:            true,
+			},
+			"tcp_flags": schema.SetAttribute{
+				MarkdownDescription: "TCP flags that must be set (fin, syn, rst, psh, ack, urg, ece, cwr)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"tcp_flags_out_of": schema.SetAttribute{
+				MarkdownDescription: "TCP flags that are checked, `tcp_flags` must be a subset",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"tcp_flags_any": schema.BoolAttribute{
+				MarkdownDescription: "Match any combination of TCP flags",
+				Optional:            true,
+			},
+			"icmp_types": schema.SetAttribute{
+				MarkdownDescription: "ICMP types to match when `protocol` is 'icmp' (e.g., 'echoreq', 'unreach')",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"icmp6_types": schema.SetAttribute{
+				MarkdownDescription: "ICMPv6 types to match when `protocol` is 'ipv6-icmp'",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"tag": schema.StringAttribute{
+				MarkdownDescription: "Tag packets matching this rule",
+				Optional:            true,
+			},
+			"tagged": schema.StringAttribute{
+				MarkdownDescription: "Only match packets carrying this tag",
+				Optional:            true,
+			},
+			"match_priority": schema.StringAttribute{
+				MarkdownDescription: "Only match packets with this priority (0-7)",
+				Optional:            true,
+			},
+			"set_priority": schema.StringAttribute{
+				MarkdownDescription: "Priority (0-7) assigned to matching packets",
+				Optional:            true,
+			},
+			"set_priority_low": schema.StringAttribute{
+				MarkdownDescription: "Priority (0-7) assigned to low-delay/ACK packets",
+				Optional:            true,
+			},
+			"dscp": schema.StringAttribute{
+				MarkdownDescription: "Match DSCP/TOS value (e.g., 'ef', 'af11', '0x10')",
+				Optional:            true,
+			},
+			"reply_to": schema.StringAttribute{
+				MarkdownDescription: "Gateway used for reply-to on this rule",
+				Optional:            true,
+			},
+			"disable_reply_to": schema.BoolAttribute{
+				MarkdownDescription: "Disable automatic reply-to on this rule",
+				Optional:            true,
+			},
+			"allow_options": schema.BoolAttribute{
+				MarkdownDescription: "Allow packets with IP options to pass",
+				Optional:            true,
+			},
+			"no_xmlrpc_sync": schema.BoolAttribute{
+				MarkdownDescription: "Exclude this rule from HA XMLRPC sync",
+				Optional:            true,
+			},
+			"no_pfsync": schema.BoolAttribute{
+				MarkdownDescription: "Do not sync states created by this rule over pfsync",
+				Optional:            true,
+			},
+			"schedule": schema.StringAttribute{
+				MarkdownDescription: "Schedule name limiting when the rule is active",
+				Optional:            true,
+			},
 		},
 	}
 }
@@ -191,8 +364,8 @@ func (r *FirewallRuleResource) Create(ctx context.Context, req resource.CreateRe
 		},
 	}
 
-	// Add sequence if provided
-	if !data.Sequence.IsNull() {
+	// Add sequence if provided (unknown means OPNsense assigns it)
+	if !data.Sequence.IsNull() && !data.Sequence.IsUnknown() {
 		ruleData["rule"].(map[string]interface{})["sequence"] = fmt.Sprintf("%d", data.Sequence.ValueInt64())
 	}
 
@@ -200,6 +373,13 @@ func (r *FirewallRuleResource) Create(ctx context.Context, req resource.CreateRe
 	if !data.Interface.IsNull() {
 		ruleData["rule"].(map[string]interface{})["interface"] = data.Interface.ValueString()
 	}
+	if !data.Interfaces.IsNull() && !data.Interfaces.IsUnknown() {
+		var interfaces []string
+		resp.Diagnostics.Append(data.Interfaces.ElementsAs(ctx, &interfaces, false)...)
+		ruleData["rule"].(map[string]interface{})["interface"] = joinSorted(interfaces)
+	}
+	// Always sent, so removing interface_not clears the inversion
+	ruleData["rule"].(map[string]interface{})["interfacenot"] = boolString(data.InterfaceNot.ValueBool())
 	if !data.Direction.IsNull() {
 		ruleData["rule"].(map[string]interface{})["direction"] = data.Direction.ValueString()
 	} else {
@@ -240,13 +420,8 @@ func (r *FirewallRuleResource) Create(ctx context.Context, req resource.CreateRe
 			ruleData["rule"].(map[string]interface{})["log"] = "0"
 		}
 	}
-	if !data.Quick.IsNull() {
-		if data.Quick.ValueBool() {
-			ruleData["rule"].(map[string]interface{})["quick"] = "1"
-		} else {
-			ruleData["rule"].(map[string]interface{})["quick"] = "0"
-		}
-	}
+	// OPNsense defaults to quick; floating rules without it are last-match
+	ruleData["rule"].(map[string]interface{})["quick"] = boolString(data.Quick.IsNull() || data.Quick.ValueBool())
 	if !data.Invert.IsNull() {
 		if data.Invert.ValueBool() {
 			ruleData["rule"].(map[string]interface{})["destination_not"] = "1"
@@ -270,55 +445,15 @@ func (r *FirewallRuleResource) Create(ctx context.Context, req resource.CreateRe
 			ruleData["rule"].(map[string]interface{})["source_not"] = "0"
 		}
 	}
-	if !data.Categories.IsNull() && !data.Categories.IsUnknown() {
-		tflog.Debug(ctx, "Categories field is present", map[string]any{
-			"isNull": data.Categories.IsNull(),
-			"isUnknown": data.Categories.IsUnknown(),
-		})
-		var categories []string
-		diags := data.Categories.ElementsAs(ctx, &categories, false)
-		resp.Diagnostics.Append(diags...)
-		
-		tflog.Debug(ctx, "After parsing categories", map[string]any{
-			"count": len(categories),
-			"hasError": diags.HasError(),
-			"categories": fmt.Sprintf("%v", categories),
-		})
-		
-		if !diags.HasError() && len(categories) > 0 {
-			// Filter out empty/invalid UUIDs
-			validCategories := make([]string, 0, len(categories))
-			for _, cat := range categories {
-				if cat != "" {
-					validCategories = append(validCategories, cat)
-					tflog.Debug(ctx, "Valid category UUID", map[string]any{"uuid": cat})
-				} else {
-					tflog.Warn(ctx, "Skipping empty category UUID")
-				}
-			}
-			
-			if len(validCategories) > 0 {
-				categoryStr := strings.Join(validCategories, ",")
-				ruleData["rule"].(map[string]interface{})["category"] = categoryStr
-				tflog.Info(ctx, "Setting categories on rule", map[string]any{
-					"categories": categoryStr,
-					"count": len(validCategories),
-				})
-			} else {
-				tflog.Warn(ctx, "No valid category UUIDs found")
-			}
-		} else if diags.HasError() {
-			tflog.Error(ctx, "Error parsing categories", map[string]any{
-				"errors": fmt.Sprintf("%v", diags.Errors()),
-			})
-		} else {
-			tflog.Warn(ctx, "Categories list is empty")
-		}
-	} else {
-		tflog.Debug(ctx, "No categories field", map[string]any{
-			"isNull": data.Categories.IsNull(),
-			"isUnknown": data.Categories.IsUnknown(),
-		})
+	// Always sent, so removing categories clears them on the firewall
+	ruleData["rule"].(map[string]interface{})["category"] = r.client.categoriesPayload(ctx, data.Categories, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.addAdvancedOptions(ctx, &data, ruleData["rule"].(map[string]interface{}), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	// Make API call to create rule
@@ -396,6 +531,8 @@ func (r *FirewallRuleResource) Create(ctx context.Context, req resource.CreateRe
 	applyReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
 	r.client.client.Do(applyReq)
 
+	r.refreshSequence(ctx, &data, &resp.Diagnostics)
+
 	tflog.Trace(ctx, "created firewall rule resource")
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -437,6 +574,36 @@ func (r *FirewallRuleResource) Read(ctx context.Context, req resource.ReadReques
 		return
 	}
 
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response: %s", err))
+		return
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response: %s\nRaw response: %s", err, string(body)))
+		return
+	}
+
+	rule, ok := result["rule"].(map[string]interface{})
+	if !ok || len(rule) == 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Sequence may be renumbered by opnsense_firewall_rule_order or the GUI
+	if seq, ok := int64Field(rule, "sequence"); ok {
+		data.Sequence = types.Int64Value(seq)
+	}
+
+	r.readInterfaces(ctx, &data, rule, &resp.Diagnostics)
+	data.Categories = r.client.categoriesFromAPI(ctx, data.Categories, rule["category"], &resp.Diagnostics)
+	r.readAdvancedOptions(ctx, &data, rule, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -459,8 +626,8 @@ func (r *FirewallRuleResource) Update(ctx context.Context, req resource.UpdateRe
 		},
 	}
 
-	// Add sequence if provided
-	if !data.Sequence.IsNull() {
+	// Add sequence if provided (unknown means OPNsense assigns it)
+	if !data.Sequence.IsNull() && !data.Sequence.IsUnknown() {
 		ruleData["rule"].(map[string]interface{})["sequence"] = fmt.Sprintf("%d", data.Sequence.ValueInt64())
 	}
 
@@ -468,6 +635,13 @@ func (r *FirewallRuleResource) Update(ctx context.Context, req resource.UpdateRe
 	if !data.Interface.IsNull() {
 		ruleData["rule"].(map[string]interface{})["interface"] = data.Interface.ValueString()
 	}
+	if !data.Interfaces.IsNull() && !data.Interfaces.IsUnknown() {
+		var interfaces []string
+		resp.Diagnostics.Append(data.Interfaces.ElementsAs(ctx, &interfaces, false)...)
+		ruleData["rule"].(map[string]interface{})["interface"] = joinSorted(interfaces)
+	}
+	// Always sent, so removing interface_not clears the inversion
+	ruleData["rule"].(map[string]interface{})["interfacenot"] = boolString(data.InterfaceNot.ValueBool())
 	if !data.Direction.IsNull() {
 		ruleData["rule"].(map[string]interface{})["direction"] = data.Direction.ValueString()
 	}
@@ -500,13 +674,8 @@ func (r *FirewallRuleResource) Update(ctx context.Context, req resource.UpdateRe
 			ruleData["rule"].(map[string]interface{})["log"] = "0"
 		}
 	}
-	if !data.Quick.IsNull() {
-		if data.Quick.ValueBool() {
-			ruleData["rule"].(map[string]interface{})["quick"] = "1"
-		} else {
-			ruleData["rule"].(map[string]interface{})["quick"] = "0"
-		}
-	}
+	// OPNsense defaults to quick; floating rules without it are last-match
+	ruleData["rule"].(map[string]interface{})["quick"] = boolString(data.Quick.IsNull() || data.Quick.ValueBool())
 	if !data.Invert.IsNull() {
 		if data.Invert.ValueBool() {
 			ruleData["rule"].(map[string]interface{})["destination_not"] = "1"
@@ -530,33 +699,26 @@ func (r *FirewallRuleResource) Update(ctx context.Context, req resource.UpdateRe
 			ruleData["rule"].(map[string]interface{})["source_not"] = "0"
 		}
 	}
-	if !data.Categories.IsNull() {
-		var categories []string
-		resp.Diagnostics.Append(data.Categories.ElementsAs(ctx, &categories, false)...)
-		if !resp.Diagnostics.HasError() && len(categories) > 0 {
-			ruleData["rule"].(map[string]interface{})["category"] = strings.Join(categories, ",")
-		}
+	// Always sent, so removing categories clears them on the firewall
+	ruleData["rule"].(map[string]interface{})["category"] = r.client.categoriesPayload(ctx, data.Categories, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	jsonData, _ := json.Marshal(ruleData)
-
-	url := fmt.Sprintf("%s/api/firewall/filter/setRule/%s", r.client.Host, data.ID.ValueString())
-	httpReq, _ := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonData)))
-	httpReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	httpReq.Header.Set("Content-Type", "application/json")
+	r.addAdvancedOptions(ctx, &data, ruleData["rule"].(map[string]interface{}), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	httpResp, err := r.client.client.Do(httpReq)
-	if err != nil {
+	// setItem checks the result, so rejected values never reach state
+	if err := r.client.setItem(ctx, "firewall/filter/setRule/"+data.ID.ValueString(), ruleData); err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update rule: %s", err))
 		return
 	}
-	defer httpResp.Body.Close()
 
-	// Apply the configuration
-	applyURL := fmt.Sprintf("%s/api/firewall/filter/apply", r.client.Host)
-	applyReq, _ := http.NewRequestWithContext(ctx, "POST", applyURL, nil)
-	applyReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	r.client.client.Do(applyReq)
+	applyFilter(ctx, r.client)
+
+	r.refreshSequence(ctx, &data, &resp.Diagnostics)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -590,4 +752,294 @@ func (r *FirewallRuleResource) Delete(ctx context.Context, req resource.DeleteRe
 
 func (r *FirewallRuleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
-}
\ No newline at end of file
+}
+
+// ValidateConfig checks interface selection and floating rule semantics.
+func (r *FirewallRuleResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data FirewallRuleResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Interface.IsNull() && !data.Interfaces.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("interfaces"),
+			"Conflicting Attributes",
+			"Only one of `interface` or `interfaces` can be set.",
+		)
+		return
+	}
+
+	if data.Interface.IsUnknown() || data.Interfaces.IsUnknown() || data.Direction.IsUnknown() {
+		return
+	}
+
+	interfaceCount := 0
+	if !data.Interface.IsNull() {
+		interfaceCount = 1
+	}
+	if !data.Interfaces.IsNull() {
+		interfaceCount = len(data.Interfaces.Elements())
+		if interfaceCount == 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("interfaces"),
+				"Invalid Attribute Value",
+				"`interfaces` must contain at least one interface, omit it for a rule on all interfaces.",
+			)
+			return
+		}
+	}
+
+	// A rule on a single interface is a regular rule, "any" only makes sense
+	// for floating rules (no interface or several interfaces)
+	if data.Direction.ValueString() == "any" && interfaceCount == 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("direction"),
+			"Invalid Attribute Value",
+			"Direction 'any' is only valid for floating rules, set `interfaces` to several interfaces or omit the interface.",
+		)
+	}
+}
+
+// ModifyPlan checks that aliases and categories referenced by the rule exist
+// on the firewall or are planned in this run, so typos fail at plan time.
+func (r *FirewallRuleResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check on destroy or before the provider is configured
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var data FirewallRuleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	portAliasRefs := func(v string) []string {
+		if name := portAliasRef(v); name != "" {
+			return []string{name}
+		}
+		return nil
+	}
+
+	type reference struct {
+		attribute string
+		value     types.String
+		aliasRefs func(string) []string
+		// Port names may be /etc/services entries, missing ones only warn
+		service bool
+	}
+	references := []reference{
+		{"source_net", data.SourceNet, networkAliasRefs, false},
+		{"destination_net", data.DestNet, networkAliasRefs, false},
+		{"source_port", data.SourcePort, portAliasRefs, true},
+		{"destination_port", data.DestPort, portAliasRefs, true},
+		{"overload_table", data.OverloadTable, networkAliasRefs, false},
+	}
+
+	for _, ref := range references {
+		// Unknown values reference objects created in this run
+		if ref.value.IsNull() || ref.value.IsUnknown() {
+			continue
+		}
+		for _, name := range ref.aliasRefs(ref.value.ValueString()) {
+			exists, err := r.client.objectExists(ctx, refKindAlias, name)
+			if err != nil {
+				resp.Diagnostics.AddWarning("Reference Check Skipped", fmt.Sprintf("Unable to list firewall aliases: %s", err))
+				return
+			}
+			switch {
+			case exists:
+			case ref.service:
+				resp.Diagnostics.AddAttributeWarning(
+					path.Root(ref.attribute),
+					"Unknown Port Alias",
+					fmt.Sprintf("%q in %s is not an alias on the firewall or in this configuration. "+
+						"This is fine for a service name from /etc/services, otherwise reference the alias resource "+
+						"(e.g. opnsense_firewall_alias.x.name) so it is created first.", name, ref.attribute),
+				)
+			default:
+				resp.Diagnostics.AddAttributeError(
+					path.Root(ref.attribute),
+					"Unknown Alias Reference",
+					fmt.Sprintf("Alias %q referenced by %s does not exist on the firewall and is not planned in this configuration. "+
+						"Reference the alias resource (e.g. opnsense_firewall_alias.x.name) so it is created first.", name, ref.attribute),
+				)
+			}
+		}
+	}
+
+	if data.Categories.IsNull() || data.Categories.IsUnknown() {
+		return
+	}
+	for _, element := range data.Categories.Elements() {
+		category, ok := element.(types.String)
+		// Category names are resolved (or auto-created) on apply
+		if !ok || category.IsNull() || category.IsUnknown() || !isUUID(category.ValueString()) {
+			continue
+		}
+		exists, err := r.client.objectExists(ctx, refKindCategory, category.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddWarning("Reference Check Skipped", fmt.Sprintf("Unable to list firewall categories: %s", err))
+			return
+		}
+		if !exists {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("categories"),
+				"Unknown Category Reference",
+				fmt.Sprintf("Category %q does not exist on the firewall and is not planned in this configuration.", category.ValueString()),
+			)
+		}
+	}
+}
+
+// readInterfaces refreshes interface selection, direction, IP version and
+// quick from a getRule payload.
+func (r *FirewallRuleResource) readInterfaces(ctx context.Context, data *FirewallRuleResourceModel, rule map[string]interface{}, diags *diag.Diagnostics) {
+	interfaces := selectedOptions(rule["interface"])
+
+	if !data.Interfaces.IsNull() {
+		var d diag.Diagnostics
+		data.Interfaces, d = setFromAPI(ctx, data.Interfaces, interfaces)
+		diags.Append(d...)
+	} else if len(interfaces) > 1 {
+		// Rule was turned into a multi-interface rule outside of Terraform
+		data.Interface = types.StringValue(strings.Join(interfaces, ","))
+	} else if len(interfaces) == 1 {
+		data.Interface = types.StringValue(interfaces[0])
+	} else {
+		data.Interface = stringFromAPI(data.Interface, "")
+	}
+
+	data.InterfaceNot = boolFromAPI(data.InterfaceNot, boolField(rule, "interfacenot"))
+	if quick := boolField(rule, "quick"); !quick || !data.Quick.IsNull() {
+		data.Quick = types.BoolValue(quick)
+	}
+	data.Direction = stringFromAPIDefault(data.Direction, selectedOption(rule["direction"]), "in")
+	data.IPProtocol = stringFromAPIDefault(data.IPProtocol, selectedOption(rule["ipprotocol"]), "inet")
+}
+
+// refreshSequence fills in the sequence OPNsense assigned when none was configured.
+func (r *FirewallRuleResource) refreshSequence(ctx context.Context, data *FirewallRuleResourceModel, diags *diag.Diagnostics) {
+	if !data.Sequence.IsUnknown() {
+		return
+	}
+
+	rule, err := r.client.getItem(ctx, "firewall/filter/getRule/"+data.ID.ValueString(), "rule")
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read rule sequence: %s", err))
+		return
+	}
+
+	seq, _ := int64Field(rule, "sequence")
+	data.Sequence = types.Int64Value(seq)
+}
+
+// addAdvancedOptions adds the advanced filter options to the rule payload.
+// Unset options are sent empty so removing them from HCL clears them.
+func (r *FirewallRuleResource) addAdvancedOptions(ctx context.Context, data *FirewallRuleResourceModel, rule map[string]interface{}, diags *diag.Diagnostics) {
+	stringOpts := map[string]types.String{
+		"statetype":    data.StateType,
+		"state-policy": data.StatePolicy,
+		"overload":     data.OverloadTable,
+		"tag":          data.Tag,
+		"tagged":       data.Tagged,
+		"prio":         data.MatchPriority,
+		"set-prio":     data.SetPriority,
+		"set-prio-low": data.SetPriorityLow,
+		"tos":          data.DSCP,
+		"reply-to":     data.ReplyTo,
+		"sched":        data.Schedule,
+	}
+	for key, v := range stringOpts {
+		rule[key] = v.ValueString()
+	}
+
+	intOpts := map[string]types.Int64{
+		"statetimeout":       data.StateTimeout,
+		"max":                data.MaxStates,
+		"max-src-nodes":      data.MaxSrcNodes,
+		"max-src-states":     data.MaxSrcStates,
+		"max-src-conn":       data.MaxSrcConn,
+		"max-src-conn-rate":  data.MaxSrcConnRate,
+		"max-src-conn-rates": data.MaxSrcConnRates,
+	}
+	for key, v := range intOpts {
+		if v.IsNull() || v.IsUnknown() {
+			rule[key] = ""
+		} else {
+			rule[key] = int64String(v.ValueInt64())
+		}
+	}
+
+	boolOpts := map[string]types.Bool{
+		"tcpflags_any":   data.TCPFlagsAny,
+		"disablereplyto": data.DisableReplyTo,
+		"allowopts":      data.AllowOptions,
+		"nosync":         data.NoXMLRPCSync,
+		"nopfsync":       data.NoPfsync,
+	}
+	for key, v := range boolOpts {
+		rule[key] = boolString(v.ValueBool())
+	}
+
+	setOpts := map[string]types.Set{
+		"tcpflags1": data.TCPFlags,
+		"tcpflags2": data.TCPFlagsOutOf,
+		"icmptype":  data.ICMPTypes,
+		"icmp6type": data.ICMP6Types,
+	}
+	for key, v := range setOpts {
+		var values []string
+		if !v.IsNull() && !v.IsUnknown() {
+			diags.Append(v.ElementsAs(ctx, &values, false)...)
+		}
+		// Multi-select fields are posted comma-separated
+		rule[key] = joinSorted(values)
+	}
+}
+
+// readAdvancedOptions refreshes the advanced filter options from a getRule payload.
+func (r *FirewallRuleResource) readAdvancedOptions(ctx context.Context, data *FirewallRuleResourceModel, rule map[string]interface{}, diags *diag.Diagnostics) {
+	// OPNsense reports "keep" when no state type was chosen
+	stateType := selectedOption(rule["statetype"])
+	if stateType == "keep" && data.StateType.IsNull() {
+		stateType = ""
+	}
+	data.StateType = stringFromAPI(data.StateType, stateType)
+	data.StatePolicy = stringFromAPI(data.StatePolicy, selectedOption(rule["state-policy"]))
+	data.OverloadTable = stringFromAPI(data.OverloadTable, selectedOption(rule["overload"]))
+	data.Tag = stringFromAPI(data.Tag, stringField(rule, "tag"))
+	data.Tagged = stringFromAPI(data.Tagged, stringField(rule, "tagged"))
+	data.MatchPriority = stringFromAPI(data.MatchPriority, selectedOption(rule["prio"]))
+	data.SetPriority = stringFromAPI(data.SetPriority, selectedOption(rule["set-prio"]))
+	data.SetPriorityLow = stringFromAPI(data.SetPriorityLow, selectedOption(rule["set-prio-low"]))
+	data.DSCP = stringFromAPI(data.DSCP, selectedOption(rule["tos"]))
+	data.ReplyTo = stringFromAPI(data.ReplyTo, selectedOption(rule["reply-to"]))
+	data.Schedule = stringFromAPI(data.Schedule, selectedOption(rule["sched"]))
+
+	data.StateTimeout = int64FromAPI(data.StateTimeout, rule, "statetimeout")
+	data.MaxStates = int64FromAPI(data.MaxStates, rule, "max")
+	data.MaxSrcNodes = int64FromAPI(data.MaxSrcNodes, rule, "max-src-nodes")
+	data.MaxSrcStates = int64FromAPI(data.MaxSrcStates, rule, "max-src-states")
+	data.MaxSrcConn = int64FromAPI(data.MaxSrcConn, rule, "max-src-conn")
+	data.MaxSrcConnRate = int64FromAPI(data.MaxSrcConnRate, rule, "max-src-conn-rate")
+	data.MaxSrcConnRates = int64FromAPI(data.MaxSrcConnRates, rule, "max-src-conn-rates")
+
+	data.TCPFlagsAny = boolFromAPI(data.TCPFlagsAny, boolField(rule, "tcpflags_any"))
+	data.DisableReplyTo = boolFromAPI(data.DisableReplyTo, boolField(rule, "disablereplyto"))
+	data.AllowOptions = boolFromAPI(data.AllowOptions, boolField(rule, "allowopts"))
+	data.NoXMLRPCSync = boolFromAPI(data.NoXMLRPCSync, boolField(rule, "nosync"))
+	data.NoPfsync = boolFromAPI(data.NoPfsync, boolField(rule, "nopfsync"))
+
+	var d diag.Diagnostics
+	data.TCPFlags, d = setFromAPI(ctx, data.TCPFlags, selectedOptions(rule["tcpflags1"]))
+	diags.Append(d...)
+	data.TCPFlagsOutOf, d = setFromAPI(ctx, data.TCPFlagsOutOf, selectedOptions(rule["tcpflags2"]))
+	diags.Append(d...)
+	data.ICMPTypes, d = setFromAPI(ctx, data.ICMPTypes, selectedOptions(rule["icmptype"]))
+	diags.Append(d...)
+	data.ICMP6Types, d = setFromAPI(ctx, data.ICMP6Types, selectedOptions(rule["icmp6type"]))
+	diags.Append(d...)
+}
@@ -0,0 +1,327 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ resource.Resource = &FirewallRuleOrderResource{}
+var _ resource.ResourceWithImportState = &FirewallRuleOrderResource{}
+
+func NewFirewallRuleOrderResource() resource.Resource {
+	return &FirewallRuleOrderResource{}
+}
+
+// FirewallRuleOrderResource owns the sequence of a set of firewall rules.
+// Rules keep being managed by opnsense_firewall_rule, this resource only
+// renumbers them so their order matches the configured list.
+type FirewallRuleOrderResource struct {
+	client *Client
+}
+
+type FirewallRuleOrderResourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	Interface     types.String `tfsdk:"interface"`
+	Category      types.String `tfsdk:"category"`
+	Rules         types.List   `tfsdk:"rules"`
+	StartSequence types.Int64  `tfsdk:"start_sequence"`
+	Step          types.Int64  `tfsdk:"step"`
+	Sequences     types.Map    `tfsdk:"sequences"`
+}
+
+func (r *FirewallRuleOrderResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_firewall_rule_order"
+}
+
+func (r *FirewallRuleOrderResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the order of a group of OPNsense firewall rules. " +
+			"Rules are renumbered so their `sequence` follows the order of `rules`; reordering done in the GUI shows up as drift. " +
+			"Do not set `sequence` on rules owned by this resource.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Identifier of the ruleset (`<interface>/<category>`)",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"interface": schema.StringAttribute{
+				MarkdownDescription: "Interface the rules belong to (e.g., 'lan'). When set, every rule must be on this interface",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"category": schema.StringAttribute{
+				MarkdownDescription: "Category UUID the rules belong to. When set, every rule must carry this category",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"rules": schema.ListAttribute{
+				MarkdownDescription: "Ordered list of firewall rule UUIDs, first rule is evaluated first",
+				Required:            true,
+				ElementType:         types.StringType,
+			},
+			"start_sequence": schema.Int64Attribute{
+				MarkdownDescription: "Sequence assigned to the first rule. Default is 100",
+				Optional:            true,
+				Computed:            true,
+				Default:             int64default.StaticInt64(100),
+			},
+			"step": schema.Int64Attribute{
+				MarkdownDescription: "Gap between consecutive sequences, leaves room for rules managed elsewhere. Default is 100",
+				Optional:            true,
+				Computed:            true,
+				Default:             int64default.StaticInt64(100),
+			},
+			"sequences": schema.MapAttribute{
+				MarkdownDescription: "Sequence currently assigned to each rule, keyed by rule UUID",
+				Computed:            true,
+				ElementType:         types.Int64Type,
+			},
+		},
+	}
+}
+
+func (r *FirewallRuleOrderResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *FirewallRuleOrderResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data FirewallRuleOrderResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%s/%s", data.Interface.ValueString(), data.Category.ValueString()))
+
+	r.applyOrder(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FirewallRuleOrderResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data FirewallRuleOrderResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var ruleIDs []string
+	resp.Diagnostics.Append(data.Rules.ElementsAs(ctx, &ruleIDs, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	type ruleSequence struct {
+		id       string
+		sequence int64
+	}
+
+	current := make([]ruleSequence, 0, len(ruleIDs))
+	sequences := make(map[string]int64, len(ruleIDs))
+	for _, id := range ruleIDs {
+		rule, err := r.client.getItem(ctx, "firewall/filter/getRule/"+id, "rule")
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read rule %s: %s", id, err))
+			return
+		}
+		if rule == nil {
+			// Dropped so the plan shows it; applying fails until it's recreated or unlisted
+			resp.Diagnostics.AddWarning("Ordered Rule Not Found",
+				fmt.Sprintf("Firewall rule %s listed in rules no longer exists. Remove it from rules or recreate it, "+
+					"otherwise the next apply fails.", id))
+			continue
+		}
+		seq, _ := int64Field(rule, "sequence")
+		current = append(current, ruleSequence{id: id, sequence: seq})
+		sequences[id] = seq
+	}
+
+	// Order by the sequence OPNsense actually has, so GUI reordering is drift
+	sort.SliceStable(current, func(i, j int) bool {
+		return current[i].sequence < current[j].sequence
+	})
+
+	ordered := make([]string, 0, len(current))
+	for _, rule := range current {
+		ordered = append(ordered, rule.id)
+	}
+
+	rules, diags := types.ListValueFrom(ctx, types.StringType, ordered)
+	resp.Diagnostics.Append(diags...)
+	seqMap, diags := types.MapValueFrom(ctx, types.Int64Type, sequences)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Rules = rules
+	data.Sequences = seqMap
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FirewallRuleOrderResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data FirewallRuleOrderResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.applyOrder(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FirewallRuleOrderResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Rules and their sequences stay as they are, only the ownership ends
+	tflog.Trace(ctx, "removed firewall rule order from state")
+}
+
+func (r *FirewallRuleOrderResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Import ID is a comma-separated list of rule UUIDs in the desired order,
+	// optionally prefixed with the scope: <interface>/<category>:<uuid>,<uuid>
+	var iface, category string
+	list := req.ID
+	if scope, rest, ok := strings.Cut(req.ID, ":"); ok {
+		iface, category, ok = strings.Cut(scope, "/")
+		if !ok {
+			resp.Diagnostics.AddError("Invalid Import ID", "Expected the scope as <interface>/<category>, either part may be empty, e.g. lan/:<uuid1>,<uuid2>")
+			return
+		}
+		list = rest
+	}
+
+	var ruleIDs []string
+	for _, id := range strings.Split(list, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ruleIDs = append(ruleIDs, id)
+		}
+	}
+	if len(ruleIDs) == 0 {
+		resp.Diagnostics.AddError("Invalid Import ID", "Expected a comma-separated list of firewall rule UUIDs, optionally prefixed with <interface>/<category>:")
+		return
+	}
+
+	rules, diags := types.ListValueFrom(ctx, types.StringType, ruleIDs)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Same id and scope as Create, so the scope attributes don't force replacement
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), iface+"/"+category)...)
+	if iface != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("interface"), iface)...)
+	}
+	if category != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("category"), category)...)
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("rules"), rules)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("start_sequence"), int64(100))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("step"), int64(100))...)
+}
+
+// applyOrder checks every rule belongs to the configured interface/category,
+// renumbers them in list order and applies the filter once.
+func (r *FirewallRuleOrderResource) applyOrder(ctx context.Context, data *FirewallRuleOrderResourceModel, diags *diag.Diagnostics) {
+	var ruleIDs []string
+	diags.Append(data.Rules.ElementsAs(ctx, &ruleIDs, false)...)
+	if diags.HasError() {
+		return
+	}
+
+	seen := make(map[string]bool, len(ruleIDs))
+	for _, id := range ruleIDs {
+		if seen[id] {
+			diags.AddError("Invalid Rules", fmt.Sprintf("Rule %s is listed more than once", id))
+			return
+		}
+		seen[id] = true
+	}
+
+	sequences := make(map[string]int64, len(ruleIDs))
+	changed := false
+	for i, id := range ruleIDs {
+		rule, err := r.client.getItem(ctx, "firewall/filter/getRule/"+id, "rule")
+		if err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to read rule %s: %s", id, err))
+			return
+		}
+		if rule == nil {
+			diags.AddError("Rule Not Found", fmt.Sprintf("Firewall rule %s does not exist", id))
+			return
+		}
+
+		if iface := data.Interface.ValueString(); iface != "" && !containsString(selectedOptions(rule["interface"]), iface) {
+			diags.AddError("Rule Not In Ruleset", fmt.Sprintf("Firewall rule %s is not on interface %q", id, iface))
+			return
+		}
+		if category := data.Category.ValueString(); category != "" && !containsString(selectedOptions(rule["category"]), category) {
+			diags.AddError("Rule Not In Ruleset", fmt.Sprintf("Firewall rule %s does not carry category %s", id, category))
+			return
+		}
+
+		want := data.StartSequence.ValueInt64() + int64(i)*data.Step.ValueInt64()
+		sequences[id] = want
+		if have, _ := int64Field(rule, "sequence"); have == want {
+			continue
+		}
+
+		// setRule only touches the fields present in the payload
+		payload := map[string]interface{}{
+			"rule": map[string]interface{}{
+				"sequence": int64String(want),
+			},
+		}
+		if err := r.client.setItem(ctx, "firewall/filter/setRule/"+id, payload); err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to set sequence of rule %s: %s", id, err))
+			return
+		}
+		changed = true
+	}
+
+	if changed {
+		applyFilter(ctx, r.client)
+	}
+
+	seqMap, d := types.MapValueFrom(ctx, types.Int64Type, sequences)
+	diags.Append(d...)
+	data.Sequences = seqMap
+}
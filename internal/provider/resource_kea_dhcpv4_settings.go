@@ -0,0 +1,290 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ resource.Resource = &KeaDhcpv4SettingsResource{}
+var _ resource.ResourceWithImportState = &KeaDhcpv4SettingsResource{}
+
+func NewKeaDhcpv4SettingsResource() resource.Resource {
+	return &KeaDhcpv4SettingsResource{}
+}
+
+// KeaDhcpv4SettingsResource manages the general settings of the Kea DHCPv4
+// server. There is only one instance per firewall; attributes left out of the
+// configuration keep their current value and are read back.
+type KeaDhcpv4SettingsResource struct {
+	client *Client
+}
+
+type KeaDhcpv4SettingsResourceModel struct {
+	ID                          types.String `tfsdk:"id"`
+	Enabled                     types.Bool   `tfsdk:"enabled"`
+	Interfaces                  types.Set    `tfsdk:"interfaces"`
+	ValidLifetime               types.Int64  `tfsdk:"valid_lifetime"`
+	FirewallRules               types.Bool   `tfsdk:"firewall_rules"`
+	SocketType                  types.String `tfsdk:"socket_type"`
+	ReclaimTimerWaitTime        types.Int64  `tfsdk:"reclaim_timer_wait_time"`
+	FlushReclaimedTimerWaitTime types.Int64  `tfsdk:"flush_reclaimed_timer_wait_time"`
+	HoldReclaimedTime           types.Int64  `tfsdk:"hold_reclaimed_time"`
+	MaxReclaimLeases            types.Int64  `tfsdk:"max_reclaim_leases"`
+	MaxReclaimTime              types.Int64  `tfsdk:"max_reclaim_time"`
+	UnwarnedReclaimCycles       types.Int64  `tfsdk:"unwarned_reclaim_cycles"`
+	HAEnabled                   types.Bool   `tfsdk:"ha_enabled"`
+	HAThisServerName            types.String `tfsdk:"ha_this_server_name"`
+	HAMaxUnackedClients         types.Int64  `tfsdk:"ha_max_unacked_clients"`
+}
+
+func (r *KeaDhcpv4SettingsResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kea_dhcpv4_settings"
+}
+
+func (r *KeaDhcpv4SettingsResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	// Every setting is optional: unset ones are left alone and read back
+	optionalInt := func(description string) schema.Int64Attribute {
+		return schema.Int64Attribute{
+			MarkdownDescription: description,
+			Optional:            true,
+			Computed:            true,
+			PlanModifiers: []planmodifier.Int64{
+				int64planmodifier.UseStateForUnknown(),
+			},
+		}
+	}
+	optionalBool := func(description string) schema.BoolAttribute {
+		return schema.BoolAttribute{
+			MarkdownDescription: description,
+			Optional:            true,
+			Computed:            true,
+			PlanModifiers: []planmodifier.Bool{
+				boolplanmodifier.UseStateForUnknown(),
+			},
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the general Kea DHCPv4 server settings in OPNsense. " +
+			"Singleton: declare it once per firewall. Settings left unset keep their current value. " +
+			"Destroying it leaves the settings in place.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Always `dhcpv4_settings`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"enabled": optionalBool("Enable the Kea DHCPv4 server"),
+			"interfaces": schema.SetAttribute{
+				MarkdownDescription: "Interfaces the server listens on (e.g., ['lan', 'opt1'])",
+				Optional:            true,
+				Computed:            true,
+				ElementType:         types.StringType,
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"valid_lifetime": optionalInt("Default lease lifetime in seconds"),
+			"firewall_rules": optionalBool("Automatically add the firewall rules DHCP needs on the listening interfaces"),
+			"socket_type": schema.StringAttribute{
+				MarkdownDescription: "Socket type: `raw` (default, needed for directly connected clients) or `udp` (relayed clients only)",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					stringOneOf("raw", "udp"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"reclaim_timer_wait_time":         optionalInt("Seconds between reclaim cycles for expired leases"),
+			"flush_reclaimed_timer_wait_time": optionalInt("Seconds between removals of reclaimed leases from the lease file"),
+			"hold_reclaimed_time":             optionalInt("Seconds reclaimed leases are kept before they are removed"),
+			"max_reclaim_leases":              optionalInt("Maximum number of leases reclaimed per cycle (0 = unlimited)"),
+			"max_reclaim_time":                optionalInt("Maximum duration of a reclaim cycle in milliseconds (0 = unlimited)"),
+			"unwarned_reclaim_cycles":         optionalInt("Number of incomplete reclaim cycles before a warning is logged"),
+			"ha_enabled":                      optionalBool("Enable the high availability hook, peers are managed with `opnsense_kea_ha_peer`"),
+			"ha_this_server_name": schema.StringAttribute{
+				MarkdownDescription: "Name of this server, must match the `name` of one of the HA peers",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"ha_max_unacked_clients": optionalInt("Number of unanswered clients after which the partner is considered down (0 = on first missed heartbeat)"),
+		},
+	}
+}
+
+func (r *KeaDhcpv4SettingsResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *KeaDhcpv4SettingsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data KeaDhcpv4SettingsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = types.StringValue("dhcpv4_settings")
+	r.apply(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KeaDhcpv4SettingsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data KeaDhcpv4SettingsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KeaDhcpv4SettingsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data KeaDhcpv4SettingsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.apply(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KeaDhcpv4SettingsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Global settings can't be removed, only the ownership ends
+	tflog.Trace(ctx, "removed Kea DHCPv4 settings from state")
+}
+
+func (r *KeaDhcpv4SettingsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// apply saves the known settings and reconfigures Kea. Subnets, reservations
+// and peers live in the same model but are left out of the payload, so they
+// stay untouched.
+func (r *KeaDhcpv4SettingsResource) apply(ctx context.Context, data *KeaDhcpv4SettingsResourceModel, diags *diag.Diagnostics) {
+	general := map[string]interface{}{}
+	putKnownBool(general, "enabled", data.Enabled)
+	putKnownInt64(general, "valid_lifetime", data.ValidLifetime)
+	putKnownBool(general, "fwrules", data.FirewallRules)
+	if !data.SocketType.IsNull() && !data.SocketType.IsUnknown() {
+		general["dhcp_socket_type"] = data.SocketType.ValueString()
+	}
+	if !data.Interfaces.IsNull() && !data.Interfaces.IsUnknown() {
+		var interfaces []string
+		diags.Append(data.Interfaces.ElementsAs(ctx, &interfaces, false)...)
+		general["interfaces"] = joinSorted(interfaces)
+	}
+
+	lexpire := map[string]interface{}{}
+	putKnownInt64(lexpire, "reclaim_timer_wait_time", data.ReclaimTimerWaitTime)
+	putKnownInt64(lexpire, "flush_reclaimed_timer_wait_time", data.FlushReclaimedTimerWaitTime)
+	putKnownInt64(lexpire, "hold_reclaimed_time", data.HoldReclaimedTime)
+	putKnownInt64(lexpire, "max_reclaim_leases", data.MaxReclaimLeases)
+	putKnownInt64(lexpire, "max_reclaim_time", data.MaxReclaimTime)
+	putKnownInt64(lexpire, "unwarned_reclaim_cycles", data.UnwarnedReclaimCycles)
+	ha := map[string]interface{}{}
+	putKnownBool(ha, "enabled", data.HAEnabled)
+	putKnownInt64(ha, "max_unacked_clients", data.HAMaxUnackedClients)
+	if !data.HAThisServerName.IsNull() && !data.HAThisServerName.IsUnknown() {
+		ha["this_server_name"] = data.HAThisServerName.ValueString()
+	}
+	if diags.HasError() {
+		return
+	}
+
+	payload := map[string]interface{}{
+		"dhcpv4": map[string]interface{}{
+			"general": general,
+			"lexpire": lexpire,
+			"ha":      ha,
+		},
+	}
+
+	if err := r.client.setItem(ctx, "kea/dhcpv4/set", payload); err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to update Kea DHCPv4 settings: %s", err))
+		return
+	}
+
+	applyKea(ctx, r.client)
+}
+
+func (r *KeaDhcpv4SettingsResource) refresh(ctx context.Context, data *KeaDhcpv4SettingsResourceModel, diags *diag.Diagnostics) {
+	settings, err := r.client.getItem(ctx, "kea/dhcpv4/get", "dhcpv4")
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read Kea DHCPv4 settings: %s", err))
+		return
+	}
+
+	general, _ := settings["general"].(map[string]interface{})
+	lexpire, _ := settings["lexpire"].(map[string]interface{})
+	ha, _ := settings["ha"].(map[string]interface{})
+
+	data.Enabled = types.BoolValue(boolField(general, "enabled"))
+	data.ValidLifetime = int64FromAPI(data.ValidLifetime, general, "valid_lifetime")
+	data.FirewallRules = types.BoolValue(boolField(general, "fwrules"))
+	data.SocketType = stringFromAPI(data.SocketType, selectedOption(general["dhcp_socket_type"]))
+
+	interfaces, d := setFromAPI(ctx, data.Interfaces, selectedOptions(general["interfaces"]))
+	diags.Append(d...)
+	data.Interfaces = interfaces
+
+	data.ReclaimTimerWaitTime = int64FromAPI(data.ReclaimTimerWaitTime, lexpire, "reclaim_timer_wait_time")
+	data.FlushReclaimedTimerWaitTime = int64FromAPI(data.FlushReclaimedTimerWaitTime, lexpire, "flush_reclaimed_timer_wait_time")
+	data.HoldReclaimedTime = int64FromAPI(data.HoldReclaimedTime, lexpire, "hold_reclaimed_time")
+	data.MaxReclaimLeases = int64FromAPI(data.MaxReclaimLeases, lexpire, "max_reclaim_leases")
+	data.MaxReclaimTime = int64FromAPI(data.MaxReclaimTime, lexpire, "max_reclaim_time")
+	data.UnwarnedReclaimCycles = int64FromAPI(data.UnwarnedReclaimCycles, lexpire, "unwarned_reclaim_cycles")
+
+	data.HAEnabled = types.BoolValue(boolField(ha, "enabled"))
+	data.HAThisServerName = stringFromAPI(data.HAThisServerName, stringField(ha, "this_server_name"))
+	data.HAMaxUnackedClients = int64FromAPI(data.HAMaxUnackedClients, ha, "max_unacked_clients")
+}
@@ -0,0 +1,212 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &KeaHAPeerResource{}
+var _ resource.ResourceWithImportState = &KeaHAPeerResource{}
+var _ resource.ResourceWithValidateConfig = &KeaHAPeerResource{}
+
+func NewKeaHAPeerResource() resource.Resource {
+	return &KeaHAPeerResource{}
+}
+
+// KeaHAPeerResource manages a peer of the Kea DHCPv4 high availability hook.
+// Both servers of a pair carry the same peer list, including themselves.
+type KeaHAPeerResource struct {
+	client *Client
+}
+
+type KeaHAPeerResourceModel struct {
+	ID   types.String `tfsdk:"id"`
+	Name types.String `tfsdk:"name"`
+	Role types.String `tfsdk:"role"`
+	URL  types.String `tfsdk:"url"`
+}
+
+func (r *KeaHAPeerResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kea_ha_peer"
+}
+
+func (r *KeaHAPeerResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages Kea DHCPv4 high availability peers in OPNsense. " +
+			"Enable HA and name this server with `opnsense_kea_dhcpv4_settings`.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Peer UUID",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Peer name, referenced by `ha_this_server_name` on the server itself",
+				Required:            true,
+			},
+			"role": schema.StringAttribute{
+				MarkdownDescription: "Role of the peer: `primary` or `standby`",
+				Required:            true,
+				Validators: []validator.String{
+					stringOneOf("primary", "standby"),
+				},
+			},
+			"url": schema.StringAttribute{
+				MarkdownDescription: "URL of the peer's Kea control agent (e.g., 'http://192.168.1.2:8001/')",
+				Required:            true,
+			},
+		},
+	}
+}
+
+func (r *KeaHAPeerResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+// ValidateConfig checks the peer name and control agent URL.
+func (r *KeaHAPeerResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data KeaHAPeerResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if v := data.Name; !v.IsNull() && !v.IsUnknown() && !hostnamePattern.MatchString(v.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("name"), "Invalid Name",
+			fmt.Sprintf("%q is not a valid peer name, use letters, digits, dots and hyphens.", v.ValueString()))
+	}
+	if v := data.URL; !v.IsNull() && !v.IsUnknown() {
+		if u, err := url.Parse(v.ValueString()); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			resp.Diagnostics.AddAttributeError(path.Root("url"), "Invalid URL",
+				fmt.Sprintf("%q is not an http(s) URL.", v.ValueString()))
+		}
+	}
+}
+
+func (r *KeaHAPeerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data KeaHAPeerResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid, err := r.client.addItem(ctx, "kea/dhcpv4/add_peer", r.mapToPayload(&data))
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create HA peer: %s", err))
+		return
+	}
+	data.ID = types.StringValue(uuid)
+
+	applyKea(ctx, r.client)
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KeaHAPeerResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data KeaHAPeerResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !r.refresh(ctx, &data, &resp.Diagnostics) {
+		if !resp.Diagnostics.HasError() {
+			resp.State.RemoveResource(ctx)
+		}
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KeaHAPeerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data KeaHAPeerResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.setItem(ctx, "kea/dhcpv4/set_peer/"+data.ID.ValueString(), r.mapToPayload(&data)); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update HA peer: %s", err))
+		return
+	}
+
+	applyKea(ctx, r.client)
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KeaHAPeerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data KeaHAPeerResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.post(ctx, "kea/dhcpv4/del_peer/"+data.ID.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete HA peer: %s", err))
+		return
+	}
+
+	applyKea(ctx, r.client)
+}
+
+func (r *KeaHAPeerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func (r *KeaHAPeerResource) mapToPayload(data *KeaHAPeerResourceModel) map[string]interface{} {
+	peer := map[string]interface{}{
+		"name": data.Name.ValueString(),
+		"role": data.Role.ValueString(),
+		"url":  data.URL.ValueString(),
+	}
+
+	return map[string]interface{}{"peer": peer}
+}
+
+// refresh reads the peer back into data, returning false when it no longer exists.
+func (r *KeaHAPeerResource) refresh(ctx context.Context, data *KeaHAPeerResourceModel, diags *diag.Diagnostics) bool {
+	peer, err := r.client.getItem(ctx, "kea/dhcpv4/get_peer/"+data.ID.ValueString(), "peer")
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read HA peer: %s", err))
+		return false
+	}
+	if peer == nil {
+		return false
+	}
+
+	data.Name = types.StringValue(stringField(peer, "name"))
+	data.Role = types.StringValue(selectedOption(peer["role"]))
+	data.URL = types.StringValue(stringField(peer, "url"))
+
+	return true
+}
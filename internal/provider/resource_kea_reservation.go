@@ -2,12 +2,12 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"net/netip"
+	"regexp"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -19,11 +19,18 @@ import (
 
 var _ resource.Resource = &KeaReservationResource{}
 var _ resource.ResourceWithImportState = &KeaReservationResource{}
+var _ resource.ResourceWithModifyPlan = &KeaReservationResource{}
+var _ resource.ResourceWithValidateConfig = &KeaReservationResource{}
+
+// Full MAC addresses, ':' or '-' separated.
+var hwAddressPattern = regexp.MustCompile(`^[0-9a-fA-F]{2}([:-][0-9a-fA-F]{2}){5}$`)
 
 func NewKeaReservationResource() resource.Resource {
 	return &KeaReservationResource{}
 }
 
+// KeaReservationResource manages Kea DHCPv4 reservations, matched on the
+// client MAC address or client identifier.
 type KeaReservationResource struct {
 	client *Client
 }
@@ -33,8 +40,10 @@ type KeaReservationResourceModel struct {
 	Subnet      types.String `tfsdk:"subnet"`
 	IPAddress   types.String `tfsdk:"ip_address"`
 	HWAddress   types.String `tfsdk:"hw_address"`
+	ClientID    types.String `tfsdk:"client_id"`
 	Hostname    types.String `tfsdk:"hostname"`
 	Description types.String `tfsdk:"description"`
+	Option      types.Object `tfsdk:"option_data"`
 }
 
 func (r *KeaReservationResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -54,16 +63,25 @@ func (r *KeaReservationResource) Schema(ctx context.Context, req resource.Schema
 				},
 			},
 			"subnet": schema.StringAttribute{
-				MarkdownDescription: "Subnet UUID this reservation belongs to",
+				MarkdownDescription: "Subnet this reservation belongs to, as UUID or CIDR (e.g., '10.0.10.0/24')",
 				Required:            true,
 			},
 			"ip_address": schema.StringAttribute{
-				MarkdownDescription: "Reserved IP address",
-				Required:            true,
+				MarkdownDescription: "Reserved IP address. When omitted, the first free address of the subnet outside " +
+					"its dynamic pools is picked on create and kept until the subnet changes",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"hw_address": schema.StringAttribute{
-				MarkdownDescription: "Hardware (MAC) address",
-				Required:            true,
+				MarkdownDescription: "Hardware (MAC) address. Exactly one of `hw_address` and `client_id` must be set",
+				Optional:            true,
+			},
+			"client_id": schema.StringAttribute{
+				MarkdownDescription: "DHCP client identifier (option 61) to match instead of the MAC address, as colon separated hex bytes (e.g., '01:aa:bb:cc:dd:ee:ff')",
+				Optional:            true,
 			},
 			"hostname": schema.StringAttribute{
 				MarkdownDescription: "Hostname for this reservation",
@@ -73,6 +91,7 @@ func (r *KeaReservationResource) Schema(ctx context.Context, req resource.Schema
 				MarkdownDescription: "Description of the reservation",
 				Optional:            true,
 			},
+			"option_data": keaOptionDataSchema("DHCP options for this client, overriding the subnet's `option_data`"),
 		},
 	}
 }
@@ -94,180 +113,190 @@ func (r *KeaReservationResource) Configure(ctx context.Context, req resource.Con
 	r.client = client
 }
 
-func (r *KeaReservationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+// ValidateConfig checks the client identifiers, the options and the address,
+// and that the address lies inside subnet when the subnet is given as a CIDR.
+func (r *KeaReservationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
 	var data KeaReservationResourceModel
-	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	reservationData := map[string]interface{}{
-		"reservation": map[string]interface{}{
-			"subnet":     data.Subnet.ValueString(),
-			"ip_address": data.IPAddress.ValueString(),
-			"hw_address": data.HWAddress.ValueString(),
-		},
+	if data.HWAddress.IsNull() && data.ClientID.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("hw_address"), "Missing Client Identifier",
+			"One of hw_address or client_id must be set.")
 	}
-
-	if !data.Hostname.IsNull() {
-		reservationData["reservation"].(map[string]interface{})["hostname"] = data.Hostname.ValueString()
+	if !data.HWAddress.IsNull() && !data.ClientID.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("client_id"), "Conflicting Client Identifiers",
+			"Only one of hw_address and client_id can be set, Kea matches a reservation on a single identifier.")
 	}
-	if !data.Description.IsNull() {
-		reservationData["reservation"].(map[string]interface{})["description"] = data.Description.ValueString()
+	if v := data.HWAddress; !v.IsNull() && !v.IsUnknown() && !hwAddressPattern.MatchString(v.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("hw_address"), "Invalid MAC Address",
+			fmt.Sprintf("%q is not a MAC address (e.g., 'aa:bb:cc:dd:ee:ff').", v.ValueString()))
 	}
+	if v := data.ClientID; !v.IsNull() && !v.IsUnknown() && !duidPattern.MatchString(v.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("client_id"), "Invalid Client ID",
+			fmt.Sprintf("%q is not a client identifier, expected colon separated hex bytes.", v.ValueString()))
+	}
+	validateKeaOptions(ctx, data.Option, path.Root("option_data"), &resp.Diagnostics)
 
-	jsonData, _ := json.Marshal(reservationData)
-
-	// Log the request for debugging
-	tflog.Debug(ctx, "Creating Kea reservation", map[string]any{
-		"endpoint": "/api/kea/dhcpv4/add_reservation",
-		"request":  string(jsonData),
-	})
+	var ip netip.Addr
+	if v := data.IPAddress; !v.IsNull() && !v.IsUnknown() {
+		var err error
+		if ip, err = netip.ParseAddr(v.ValueString()); err != nil || !ip.Is4() {
+			resp.Diagnostics.AddAttributeError(path.Root("ip_address"), "Invalid Address",
+				fmt.Sprintf("%q is not an IPv4 address.", v.ValueString()))
+			return
+		}
+	}
+	if v := data.Subnet; !v.IsNull() && !v.IsUnknown() && strings.Contains(v.ValueString(), "/") {
+		network, err := netip.ParsePrefix(v.ValueString())
+		if err != nil || !network.Addr().Is4() {
+			resp.Diagnostics.AddAttributeError(path.Root("subnet"), "Invalid Subnet",
+				fmt.Sprintf("%q is neither a subnet UUID nor an IPv4 network in CIDR notation.", v.ValueString()))
+			return
+		}
+		if ip.IsValid() && !network.Masked().Contains(ip) {
+			resp.Diagnostics.AddAttributeError(path.Root("ip_address"), "Address Outside Subnet",
+				fmt.Sprintf("%s is not inside subnet %s.", ip, network.Masked()))
+		}
+	}
+}
 
-	url := fmt.Sprintf("%s/api/kea/dhcpv4/add_reservation", r.client.Host)
-	httpReq, _ := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonData)))
-	httpReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	httpReq.Header.Set("Content-Type", "application/json")
+// ModifyPlan checks the reservation against the firewall: the subnet must
+// exist and contain ip_address, the address must not be reserved elsewhere,
+// and the MAC address or client ID must not have another reservation in the
+// subnet. It also allocates a new address when ip_address is left to the
+// provider and the subnet changes.
+func (r *KeaReservationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
 
-	httpResp, err := r.client.client.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create reservation: %s", err))
+	var config, plan KeaReservationResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.Subnet.IsUnknown() {
 		return
 	}
-	defer httpResp.Body.Close()
 
-	body, err := io.ReadAll(httpResp.Body)
+	subnetID, network, ok, err := r.client.keaSubnet4(ctx, plan.Subnet.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response: %s", err))
+		resp.Diagnostics.AddWarning("Reservation Check Skipped", fmt.Sprintf("Unable to look up subnet %s: %s", plan.Subnet.ValueString(), err))
+		return
+	}
+	if !ok {
+		if network.IsValid() {
+			// Probably created in this run; referencing the subnet resource
+			// orders the two.
+			resp.Diagnostics.AddAttributeWarning(path.Root("subnet"), "Subnet Not Found",
+				fmt.Sprintf("No Kea subnet %s exists yet. Reference the opnsense_kea_subnet resource so it is created first.", network))
+		} else {
+			resp.Diagnostics.AddAttributeError(path.Root("subnet"), "Subnet Not Found",
+				fmt.Sprintf("No Kea subnet with UUID %s exists.", plan.Subnet.ValueString()))
+		}
 		return
 	}
 
-	// Log raw response
-	tflog.Debug(ctx, "Kea API Response", map[string]any{
-		"status_code": httpResp.StatusCode,
-		"body":        string(body),
-	})
+	var state KeaReservationResourceModel
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if config.IPAddress.IsNull() && !plan.Subnet.Equal(state.Subnet) {
+			if stateID, _, _, err := r.client.keaSubnet4(ctx, state.Subnet.ValueString()); err != nil || stateID != subnetID {
+				plan.IPAddress = types.StringUnknown()
+				resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("ip_address"), plan.IPAddress)...)
+			}
+		}
+	}
 
-	if httpResp.StatusCode != 200 {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("API returned status %d: %s", httpResp.StatusCode, string(body)))
+	ip, _ := netip.ParseAddr(plan.IPAddress.ValueString())
+	if ip.IsValid() && network.IsValid() && !network.Contains(ip) {
+		resp.Diagnostics.AddAttributeError(path.Root("ip_address"), "Address Outside Subnet",
+			fmt.Sprintf("%s is not inside subnet %s.", ip, network))
 		return
 	}
 
-	// Check if response is empty or just whitespace
-	if len(strings.TrimSpace(string(body))) == 0 {
-		resp.Diagnostics.AddError("API Error", "API returned empty response")
+	rows, err := r.client.searchItems(ctx, "kea/dhcpv4/search_reservation")
+	if err != nil {
+		resp.Diagnostics.AddWarning("Reservation Check Skipped", fmt.Sprintf("Unable to list reservations: %s", err))
 		return
 	}
 
-	// Try to determine what kind of response we got
-	firstChar := strings.TrimSpace(string(body))[0]
-	
-	if firstChar == '[' {
-		// It's an array response - likely validation errors or empty response
-		var resultArray []interface{}
-		if err := json.Unmarshal(body, &resultArray); err != nil {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse array response: %s\nRaw response: %s", err, string(body)))
-			return
+	mac := normalizeMAC(plan.HWAddress.ValueString())
+	clientID := plan.ClientID.ValueString()
+	for _, row := range rows {
+		if uuid := stringField(row, "uuid"); uuid == "" || uuid == state.ID.ValueString() {
+			continue
 		}
-		
-		if len(resultArray) == 0 {
-			resp.Diagnostics.AddError(
-				"Kea DHCP API Error",
-				"API returned empty array [].\n\n"+
-				"This typically means:\n"+
-				"1. The Kea DHCP plugin is not installed or enabled in OPNsense\n"+
-				"2. The subnet UUID referenced doesn't exist\n"+
-				"3. Request validation failed\n\n"+
-				"To fix:\n"+
-				"- In OPNsense GUI: System > Firmware > Plugins\n"+
-				"- Install 'os-kea-dhcp' plugin if not already installed\n"+
-				"- Ensure the referenced subnet exists first\n"+
-				"- Check Services > Kea DHCPv4 to ensure it's configured\n\n"+
-				"Request sent: "+string(jsonData),
-			)
-			return
+		owner := stringField(row, "hostname")
+		if owner == "" {
+			owner = stringField(row, "uuid")
 		}
-		
-		// Try to extract error messages from array
-		var errorMessages []string
-		for _, item := range resultArray {
-			if errMap, ok := item.(map[string]interface{}); ok {
-				if msg, ok := errMap["message"].(string); ok {
-					errorMessages = append(errorMessages, msg)
-				}
+		if other, err := netip.ParseAddr(stringField(row, "ip_address")); err == nil && other == ip {
+			client := stringField(row, "hw_address")
+			if client == "" {
+				client = stringField(row, "client_id")
 			}
+			resp.Diagnostics.AddAttributeError(path.Root("ip_address"), "Address Already Reserved",
+				fmt.Sprintf("%s is already reserved for %s (%s).", ip, client, owner))
 		}
-		
-		if len(errorMessages) > 0 {
-			resp.Diagnostics.AddError("API Validation Error", fmt.Sprintf("API returned errors: %s", strings.Join(errorMessages, ", ")))
-		} else {
-			resp.Diagnostics.AddError("API Error", fmt.Sprintf("API returned unexpected array response: %s", string(body)))
+		rowSubnet := stringField(row, "subnet")
+		sameSubnet := rowSubnet == subnetID || (network.IsValid() && normalizePrefix(rowSubnet) == network.String())
+		if mac != "" && sameSubnet && normalizeMAC(stringField(row, "hw_address")) == mac {
+			resp.Diagnostics.AddAttributeError(path.Root("hw_address"), "MAC Address Already Reserved",
+				fmt.Sprintf("%s already has a reservation in %s (%s, %s).",
+					plan.HWAddress.ValueString(), plan.Subnet.ValueString(), stringField(row, "ip_address"), owner))
+		}
+		if clientID != "" && sameSubnet && strings.EqualFold(stringField(row, "client_id"), clientID) {
+			resp.Diagnostics.AddAttributeError(path.Root("client_id"), "Client ID Already Reserved",
+				fmt.Sprintf("%s already has a reservation in %s (%s, %s).",
+					clientID, plan.Subnet.ValueString(), stringField(row, "ip_address"), owner))
 		}
-		return
 	}
+}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response: %s\nRaw response: %s", err, string(body)))
+func (r *KeaReservationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data KeaReservationResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Check for validation errors in result
-	if resultStatus, ok := result["result"].(string); ok && resultStatus == "failed" {
-		var errorMsgs []string
-		if validations, ok := result["validations"].(map[string]interface{}); ok {
-			for field, errors := range validations {
-				if errList, ok := errors.([]interface{}); ok {
-					for _, err := range errList {
-						if errStr, ok := err.(string); ok {
-							errorMsgs = append(errorMsgs, fmt.Sprintf("%s: %s", field, errStr))
-						}
-					}
-				} else if errStr, ok := errors.(string); ok {
-					errorMsgs = append(errorMsgs, fmt.Sprintf("%s: %s", field, errStr))
-				}
-			}
-		}
-		if len(errorMsgs) > 0 {
-			resp.Diagnostics.AddError("API Validation Failed", fmt.Sprintf("Validation errors:\n- %s", strings.Join(errorMsgs, "\n- ")))
-		} else {
-			resp.Diagnostics.AddError("API Error", fmt.Sprintf("API returned failed status: %s", string(body)))
-		}
+	subnetID, err := r.subnetID(ctx, &data)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create reservation: %s", err))
 		return
 	}
 
-	// Log the full response for debugging
-	if resultJSON, err := json.MarshalIndent(result, "", "  "); err == nil {
-		tflog.Debug(ctx, "API Response", map[string]any{"response": string(resultJSON)})
+	keaAllocMu.Lock()
+	defer keaAllocMu.Unlock()
+
+	if err := r.allocateAddress(ctx, &data, subnetID); err != nil {
+		resp.Diagnostics.AddError("Address Allocation Failed", fmt.Sprintf("Unable to pick an address for the reservation: %s", err))
+		return
 	}
 
-	// Try to extract UUID from various possible response formats
-	var uuid string
-	if uuidVal, ok := result["uuid"].(string); ok {
-		uuid = uuidVal
-	} else if uuidVal, ok := result["id"].(string); ok {
-		uuid = uuidVal
-	} else if resultVal, ok := result["result"].(string); ok {
-		uuid = resultVal
-	} else if reservationData, ok := result["reservation"].(map[string]interface{}); ok {
-		if uuidVal, ok := reservationData["uuid"].(string); ok {
-			uuid = uuidVal
-		}
+	payload := r.mapToPayload(ctx, &data, subnetID, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if uuid != "" {
-		data.ID = types.StringValue(uuid)
-	} else {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("No UUID found in API response. Response: %s", string(body)))
+	uuid, err := r.client.addItem(ctx, "kea/dhcpv4/add_reservation", payload)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create reservation: %s", err))
 		return
 	}
+	data.ID = types.StringValue(uuid)
 
-	// Apply configuration
-	applyURL := fmt.Sprintf("%s/api/kea/service/reconfigure", r.client.Host)
-	applyReq, _ := http.NewRequestWithContext(ctx, "POST", applyURL, nil)
-	applyReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	r.client.client.Do(applyReq)
+	applyKea(ctx, r.client)
 
+	r.refresh(ctx, &data, &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -278,167 +307,162 @@ func (r *KeaReservationResource) Read(ctx context.Context, req resource.ReadRequ
 		return
 	}
 
-	url := fmt.Sprintf("%s/api/kea/dhcpv4/get_reservation/%s", r.client.Host, data.ID.ValueString())
-	httpReq, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
-	httpReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-
-	httpResp, err := r.client.client.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read reservation: %s", err))
+	if !r.refresh(ctx, &data, &resp.Diagnostics) {
+		if !resp.Diagnostics.HasError() {
+			resp.State.RemoveResource(ctx)
+		}
 		return
 	}
-	defer httpResp.Body.Close()
 
-	if httpResp.StatusCode == 404 {
-		resp.State.RemoveResource(ctx)
-		return
-	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
 
-	if httpResp.StatusCode != 200 {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("API returned status %d", httpResp.StatusCode))
+func (r *KeaReservationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data KeaReservationResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	body, err := io.ReadAll(httpResp.Body)
+	subnetID, err := r.subnetID(ctx, &data)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response: %s", err))
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update reservation: %s", err))
 		return
 	}
 
-	// Log raw response
-	tflog.Debug(ctx, "Kea reservation Read response", map[string]any{
-		"status_code": httpResp.StatusCode,
-		"body":        string(body),
-	})
+	keaAllocMu.Lock()
+	defer keaAllocMu.Unlock()
 
-	// Check if response is empty
-	if len(strings.TrimSpace(string(body))) == 0 {
-		// Empty response might mean the reservation doesn't exist anymore
-		resp.State.RemoveResource(ctx)
+	if err := r.allocateAddress(ctx, &data, subnetID); err != nil {
+		resp.Diagnostics.AddError("Address Allocation Failed", fmt.Sprintf("Unable to pick an address for the reservation: %s", err))
 		return
 	}
 
-	// Try to determine what kind of response we got
-	firstChar := strings.TrimSpace(string(body))[0]
-	
-	if firstChar == '[' {
-		// Array response - likely means resource doesn't exist or error
-		tflog.Warn(ctx, "Kea reservation returned array, removing from state", map[string]any{
-			"id": data.ID.ValueString(),
-		})
-		resp.State.RemoveResource(ctx)
+	payload := r.mapToPayload(ctx, &data, subnetID, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response: %s\nRaw response: %s", err, string(body)))
+	if err := r.client.setItem(ctx, "kea/dhcpv4/set_reservation/"+data.ID.ValueString(), payload); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update reservation: %s", err))
 		return
 	}
 
-	// Parse the reservation data from the response
-	if reservationData, ok := result["reservation"].(map[string]interface{}); ok {
-		if subnet, ok := reservationData["subnet"].(string); ok {
-			data.Subnet = types.StringValue(subnet)
-		}
-		if ipAddress, ok := reservationData["ip_address"].(string); ok {
-			data.IPAddress = types.StringValue(ipAddress)
-		}
-		if hwAddress, ok := reservationData["hw_address"].(string); ok {
-			data.HWAddress = types.StringValue(hwAddress)
-		}
-		if hostname, ok := reservationData["hostname"].(string); ok {
-			data.Hostname = types.StringValue(hostname)
-		}
-		if description, ok := reservationData["description"].(string); ok {
-			data.Description = types.StringValue(description)
-		}
-	}
+	applyKea(ctx, r.client)
 
+	r.refresh(ctx, &data, &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
-func (r *KeaReservationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+func (r *KeaReservationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var data KeaReservationResourceModel
-	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	reservationData := map[string]interface{}{
-		"reservation": map[string]interface{}{
-			"subnet":     data.Subnet.ValueString(),
-			"ip_address": data.IPAddress.ValueString(),
-			"hw_address": data.HWAddress.ValueString(),
-		},
+	if err := r.client.post(ctx, "kea/dhcpv4/del_reservation/"+data.ID.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete reservation: %s", err))
+		return
 	}
 
-	if !data.Hostname.IsNull() {
-		reservationData["reservation"].(map[string]interface{})["hostname"] = data.Hostname.ValueString()
-	}
-	if !data.Description.IsNull() {
-		reservationData["reservation"].(map[string]interface{})["description"] = data.Description.ValueString()
-	}
+	applyKea(ctx, r.client)
+}
 
-	jsonData, _ := json.Marshal(reservationData)
+func (r *KeaReservationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
 
-	url := fmt.Sprintf("%s/api/kea/dhcpv4/set_reservation/%s", r.client.Host, data.ID.ValueString())
-	httpReq, _ := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonData)))
-	httpReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	httpReq.Header.Set("Content-Type", "application/json")
+// mapToPayload builds the reservation payload. MAC addresses and client IDs
+// are sent in the lower-case, colon separated form OPNsense stores them in.
+func (r *KeaReservationResource) mapToPayload(ctx context.Context, data *KeaReservationResourceModel, subnetID string, diags *diag.Diagnostics) map[string]interface{} {
+	reservation := map[string]interface{}{
+		"subnet":      subnetID,
+		"ip_address":  data.IPAddress.ValueString(),
+		"hw_address":  normalizeMAC(data.HWAddress.ValueString()),
+		"client_id":   strings.ToLower(data.ClientID.ValueString()),
+		"hostname":    data.Hostname.ValueString(),
+		"description": data.Description.ValueString(),
+		"option_data": keaOptionsPayload(ctx, data.Option, diags),
+	}
 
-	httpResp, err := r.client.client.Do(httpReq)
+	return map[string]interface{}{"reservation": reservation}
+}
+
+// refresh reads the reservation back into data, returning false when it no
+// longer exists. Every attribute is refreshed, so imported reservations plan
+// clean; addresses, MAC addresses and client IDs keep the configured
+// spelling when they only differ in case or notation.
+func (r *KeaReservationResource) refresh(ctx context.Context, data *KeaReservationResourceModel, diags *diag.Diagnostics) bool {
+	reservation, err := r.client.getItem(ctx, "kea/dhcpv4/get_reservation/"+data.ID.ValueString(), "reservation")
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update reservation: %s", err))
-		return
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read reservation: %s", err))
+		return false
+	}
+	if reservation == nil {
+		return false
 	}
-	defer httpResp.Body.Close()
 
-	body, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response: %s", err))
-		return
+	data.Subnet = r.subnetFromAPI(ctx, data.Subnet, selectedOption(reservation["subnet"]))
+
+	ip := stringField(reservation, "ip_address")
+	configured, _ := netip.ParseAddr(data.IPAddress.ValueString())
+	if a, err := netip.ParseAddr(ip); err != nil || a != configured {
+		data.IPAddress = types.StringValue(ip)
 	}
 
-	if httpResp.StatusCode != 200 {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("API returned status %d: %s", httpResp.StatusCode, string(body)))
-		return
+	if mac := normalizeMAC(stringField(reservation, "hw_address")); mac != normalizeMAC(data.HWAddress.ValueString()) {
+		data.HWAddress = stringFromAPI(data.HWAddress, mac)
+	}
+	if clientID := stringField(reservation, "client_id"); !strings.EqualFold(clientID, data.ClientID.ValueString()) {
+		data.ClientID = stringFromAPI(data.ClientID, clientID)
 	}
 
-	// Apply configuration
-	applyURL := fmt.Sprintf("%s/api/kea/service/reconfigure", r.client.Host)
-	applyReq, _ := http.NewRequestWithContext(ctx, "POST", applyURL, nil)
-	applyReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	r.client.client.Do(applyReq)
+	data.Hostname = stringFromAPI(data.Hostname, stringField(reservation, "hostname"))
+	data.Description = stringFromAPI(data.Description, stringField(reservation, "description"))
+	data.Option = keaOptionsFromAPI(ctx, data.Option, reservation["option_data"], diags)
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	return true
 }
 
-func (r *KeaReservationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var data KeaReservationResourceModel
-	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
-	if resp.Diagnostics.HasError() {
-		return
+// subnetID resolves the configured subnet, a UUID or a CIDR, to its UUID.
+func (r *KeaReservationResource) subnetID(ctx context.Context, data *KeaReservationResourceModel) (string, error) {
+	id, _, ok, err := r.client.keaSubnet4(ctx, data.Subnet.ValueString())
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("no Kea subnet %s", data.Subnet.ValueString())
+	}
+	return id, nil
+}
+
+// subnetFromAPI keeps the configured subnet reference, UUID or CIDR, as long
+// as it still resolves to the subnet the reservation belongs to.
+func (r *KeaReservationResource) subnetFromAPI(ctx context.Context, current types.String, id string) types.String {
+	if current.ValueString() == id {
+		return current
+	}
+	if !current.IsNull() && strings.Contains(current.ValueString(), "/") {
+		if resolved, _, ok, err := r.client.keaSubnet4(ctx, current.ValueString()); err == nil && ok && resolved == id {
+			return current
+		}
 	}
+	return types.StringValue(id)
+}
 
-	url := fmt.Sprintf("%s/api/kea/dhcpv4/del_reservation/%s", r.client.Host, data.ID.ValueString())
-	httpReq, _ := http.NewRequestWithContext(ctx, "POST", url, nil)
-	httpReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
+// allocateAddress fills in ip_address when it is left to the provider.
+// Callers hold keaAllocMu until the reservation is saved.
+func (r *KeaReservationResource) allocateAddress(ctx context.Context, data *KeaReservationResourceModel, subnetID string) error {
+	if !data.IPAddress.IsUnknown() && !data.IPAddress.IsNull() {
+		return nil
+	}
 
-	httpResp, err := r.client.client.Do(httpReq)
+	ip, err := r.client.nextFreeKeaAddress(ctx, subnetID)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete reservation: %s", err))
-		return
+		return err
 	}
-	defer httpResp.Body.Close()
-
-	// Apply configuration
-	applyURL := fmt.Sprintf("%s/api/kea/service/reconfigure", r.client.Host)
-	applyReq, _ := http.NewRequestWithContext(ctx, "POST", applyURL, nil)
-	applyReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	r.client.client.Do(applyReq)
+	tflog.Debug(ctx, "Allocated Kea reservation address", map[string]any{"subnet": subnetID, "ip_address": ip})
+	data.IPAddress = types.StringValue(ip)
+	return nil
 }
-
-func (r *KeaReservationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
-}
\ No newline at end of file
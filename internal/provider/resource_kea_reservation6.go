@@ -0,0 +1,242 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &KeaReservation6Resource{}
+var _ resource.ResourceWithImportState = &KeaReservation6Resource{}
+var _ resource.ResourceWithValidateConfig = &KeaReservation6Resource{}
+
+// DHCP unique identifiers: colon separated hex bytes.
+var duidPattern = regexp.MustCompile(`^[0-9a-fA-F]{2}(:[0-9a-fA-F]{2})+$`)
+
+func NewKeaReservation6Resource() resource.Resource {
+	return &KeaReservation6Resource{}
+}
+
+// KeaReservation6Resource manages Kea DHCPv6 reservations, which match
+// clients on their DUID rather than their MAC address.
+type KeaReservation6Resource struct {
+	client *Client
+}
+
+type KeaReservation6ResourceModel struct {
+	ID          types.String `tfsdk:"id"`
+	Subnet      types.String `tfsdk:"subnet"`
+	IPAddress   types.String `tfsdk:"ip_address"`
+	DUID        types.String `tfsdk:"duid"`
+	Hostname    types.String `tfsdk:"hostname"`
+	Description types.String `tfsdk:"description"`
+}
+
+func (r *KeaReservation6Resource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kea_reservation6"
+}
+
+func (r *KeaReservation6Resource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages Kea DHCPv6 reservations in OPNsense",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Reservation UUID",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"subnet": schema.StringAttribute{
+				MarkdownDescription: "UUID of the `opnsense_kea_subnet6` this reservation belongs to",
+				Required:            true,
+			},
+			"ip_address": schema.StringAttribute{
+				MarkdownDescription: "Reserved IPv6 address",
+				Required:            true,
+			},
+			"duid": schema.StringAttribute{
+				MarkdownDescription: "DHCP unique identifier of the client (e.g., '00:01:00:01:2c:1f:aa:bb:cc:dd:ee:ff')",
+				Required:            true,
+			},
+			"hostname": schema.StringAttribute{
+				MarkdownDescription: "Hostname for this reservation",
+				Optional:            true,
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Description of the reservation",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (r *KeaReservation6Resource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+// ValidateConfig checks the address, DUID and hostname.
+func (r *KeaReservation6Resource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data KeaReservation6ResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if v := data.IPAddress; !v.IsNull() && !v.IsUnknown() {
+		if ip, err := netip.ParseAddr(v.ValueString()); err != nil || !ip.Is6() || ip.Is4In6() {
+			resp.Diagnostics.AddAttributeError(path.Root("ip_address"), "Invalid Address",
+				fmt.Sprintf("%q is not an IPv6 address.", v.ValueString()))
+		}
+	}
+	if v := data.DUID; !v.IsNull() && !v.IsUnknown() && !duidPattern.MatchString(v.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("duid"), "Invalid DUID",
+			fmt.Sprintf("%q is not a DUID, expected colon separated hex bytes.", v.ValueString()))
+	}
+	if v := data.Hostname; !v.IsNull() && !v.IsUnknown() && !hostnamePattern.MatchString(v.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("hostname"), "Invalid Hostname",
+			fmt.Sprintf("%q is not a valid host name.", v.ValueString()))
+	}
+}
+
+func (r *KeaReservation6Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data KeaReservation6ResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid, err := r.client.addItem(ctx, "kea/dhcpv6/add_reservation", r.mapToPayload(&data))
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create reservation: %s", err))
+		return
+	}
+	data.ID = types.StringValue(uuid)
+
+	applyKea(ctx, r.client)
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KeaReservation6Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data KeaReservation6ResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !r.refresh(ctx, &data, &resp.Diagnostics) {
+		if !resp.Diagnostics.HasError() {
+			resp.State.RemoveResource(ctx)
+		}
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KeaReservation6Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data KeaReservation6ResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.setItem(ctx, "kea/dhcpv6/set_reservation/"+data.ID.ValueString(), r.mapToPayload(&data)); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update reservation: %s", err))
+		return
+	}
+
+	applyKea(ctx, r.client)
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KeaReservation6Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data KeaReservation6ResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.post(ctx, "kea/dhcpv6/del_reservation/"+data.ID.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete reservation: %s", err))
+		return
+	}
+
+	applyKea(ctx, r.client)
+}
+
+func (r *KeaReservation6Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func (r *KeaReservation6Resource) mapToPayload(data *KeaReservation6ResourceModel) map[string]interface{} {
+	reservation := map[string]interface{}{
+		"subnet":      data.Subnet.ValueString(),
+		"ip_address":  data.IPAddress.ValueString(),
+		"duid":        data.DUID.ValueString(),
+		"hostname":    data.Hostname.ValueString(),
+		"description": data.Description.ValueString(),
+	}
+
+	return map[string]interface{}{"reservation": reservation}
+}
+
+// refresh reads the reservation back into data, returning false when it no
+// longer exists. Addresses and DUIDs keep the configured spelling when they
+// only differ in case or notation.
+func (r *KeaReservation6Resource) refresh(ctx context.Context, data *KeaReservation6ResourceModel, diags *diag.Diagnostics) bool {
+	reservation, err := r.client.getItem(ctx, "kea/dhcpv6/get_reservation/"+data.ID.ValueString(), "reservation")
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read reservation: %s", err))
+		return false
+	}
+	if reservation == nil {
+		return false
+	}
+
+	data.Subnet = types.StringValue(selectedOption(reservation["subnet"]))
+
+	ip := stringField(reservation, "ip_address")
+	configured, _ := netip.ParseAddr(data.IPAddress.ValueString())
+	if a, err := netip.ParseAddr(ip); err != nil || a != configured {
+		data.IPAddress = types.StringValue(ip)
+	}
+
+	duid := stringField(reservation, "duid")
+	if !strings.EqualFold(duid, data.DUID.ValueString()) {
+		data.DUID = types.StringValue(duid)
+	}
+
+	data.Hostname = stringFromAPI(data.Hostname, stringField(reservation, "hostname"))
+	data.Description = stringFromAPI(data.Description, stringField(reservation, "description"))
+
+	return true
+}
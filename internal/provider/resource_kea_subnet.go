@@ -2,23 +2,27 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"net"
+	"slices"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var _ resource.Resource = &KeaSubnetResource{}
 var _ resource.ResourceWithImportState = &KeaSubnetResource{}
+var _ resource.ResourceWithValidateConfig = &KeaSubnetResource{}
+var _ resource.ResourceWithModifyPlan = &KeaSubnetResource{}
+var _ resource.ResourceWithUpgradeState = &KeaSubnetResource{}
 
 func NewKeaSubnetResource() resource.Resource {
 	return &KeaSubnetResource{}
@@ -31,8 +35,8 @@ type KeaSubnetResource struct {
 type KeaSubnetResourceModel struct {
 	ID          types.String `tfsdk:"id"`
 	Subnet      types.String `tfsdk:"subnet"`
-	Pools       types.String `tfsdk:"pools"`
-	Option      types.Map    `tfsdk:"option_data"`
+	Pools       types.List   `tfsdk:"pools"`
+	Option      types.Object `tfsdk:"option_data"`
 	AutoCollect types.Bool   `tfsdk:"auto_collect"`
 	Description types.String `tfsdk:"description"`
 }
@@ -43,173 +47,306 @@ func (r *KeaSubnetResource) Metadata(ctx context.Context, req resource.MetadataR
 
 func (r *KeaSubnetResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages Kea DHCPv4 subnets in OPNsense",
+		// Version 1 types option_data and pools, see UpgradeState
+		Version: 1,
+
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
-				Computed: true,
-				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				Computed:            true,
+				MarkdownDescription: "Subnet UUID",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"subnet":      schema.StringAttribute{Required: true},
-			"pools":       schema.StringAttribute{Optional: true},
-			"description": schema.StringAttribute{Optional: true},
-			"auto_collect": schema.BoolAttribute{
-				Optional: true,
-				Computed: true,
+			"subnet": schema.StringAttribute{
+				MarkdownDescription: "IPv4 network in CIDR notation (e.g., '10.0.10.0/24')",
+				Required:            true,
+			},
+			"pools": keaPoolsSchema("Dynamic address pools. Each must lie inside `subnet` and not overlap other pools or reserved addresses"),
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Description of the subnet",
+				Optional:            true,
 			},
-			"option_data": schema.MapAttribute{
-				ElementType: types.StringType,
-				Optional:    true,
+			"auto_collect": schema.BoolAttribute{
+				MarkdownDescription: "Fill in routers, DNS and NTP servers from the interface address when not set in `option_data` (default: true)",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
 			},
+			"option_data": keaOptionDataSchema("DHCP options handed out to clients of this subnet"),
 		},
 	}
 }
 
 func (r *KeaSubnetResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	if req.ProviderData == nil { return }
+	if req.ProviderData == nil {
+		return
+	}
+
 	client, ok := req.ProviderData.(*Client)
 	if !ok {
-		resp.Diagnostics.AddError("Type Error", "Expected *Client")
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T.", req.ProviderData),
+		)
 		return
 	}
+
 	r.client = client
 }
 
-func (r *KeaSubnetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+// ValidateConfig checks the subnet and the option values.
+func (r *KeaSubnetResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data KeaSubnetResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Subnet.IsNull() && !data.Subnet.IsUnknown() {
+		if ip, _, err := net.ParseCIDR(data.Subnet.ValueString()); err != nil || ip.To4() == nil {
+			resp.Diagnostics.AddAttributeError(path.Root("subnet"), "Invalid Subnet",
+				fmt.Sprintf("%q is not an IPv4 network in CIDR notation.", data.Subnet.ValueString()))
+		}
+	}
+
+	validateKeaPools(ctx, data.Pools, data.Subnet, path.Root("pools"), &resp.Diagnostics)
+	validateKeaOptions(ctx, data.Option, path.Root("option_data"), &resp.Diagnostics)
+}
+
+// ModifyPlan rejects new or changed pools containing addresses that are
+// already reserved, Kea would otherwise hand them out dynamically as well.
+func (r *KeaSubnetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check on destroy or before the provider is configured
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
 	var data KeaSubnetResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
-	if resp.Diagnostics.HasError() { return }
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Unchanged pools were checked when they were planned
+	if !req.State.Raw.IsNull() {
+		var pools types.List
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("pools"), &pools)...)
+		if resp.Diagnostics.HasError() || data.Pools.Equal(pools) {
+			return
+		}
+	}
 
-	payload := r.mapToPayload(ctx, &data)
-	jsonData, _ := json.Marshal(payload)
+	checkKeaPoolReservations(ctx, r.client, "dhcpv4", data.Pools, path.Root("pools"), &resp.Diagnostics)
+}
 
-	url := fmt.Sprintf("%s/api/kea/dhcpv4/add_subnet", r.client.Host)
-	body := r.doRequest(ctx, "POST", url, jsonData, &resp.Diagnostics)
-	if resp.Diagnostics.HasError() { return }
+func (r *KeaSubnetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data KeaSubnetResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	var result map[string]interface{}
-	json.Unmarshal(body, &result)
+	payload := r.mapToPayload(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	// OPNsense returns UUID in 'uuid' field
-	if uuid, ok := result["uuid"].(string); ok {
-		data.ID = types.StringValue(uuid)
+	uuid, err := r.client.addItem(ctx, "kea/dhcpv4/add_subnet", payload)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create subnet: %s", err))
+		return
 	}
+	data.ID = types.StringValue(uuid)
+
+	applyKea(ctx, r.client)
 
-	r.reconfigureService(ctx)
+	r.refresh(ctx, &data, &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *KeaSubnetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var data KeaSubnetResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
-	if resp.Diagnostics.HasError() { return }
-
-	url := fmt.Sprintf("%s/api/kea/dhcpv4/get_subnet/%s", r.client.Host, data.ID.ValueString())
-	body := r.doRequest(ctx, "GET", url, nil, &resp.Diagnostics)
-	if resp.Diagnostics.HasError() || body == nil { return }
-
-	var result map[string]interface{}
-	json.Unmarshal(body, &result)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	if subnetData, ok := result["subnet4"].(map[string]interface{}); ok {
-		data.Subnet = types.StringValue(subnetData["subnet"].(string))
-		data.Pools = types.StringValue(subnetData["pools"].(string))
-		data.Description = types.StringValue(subnetData["description"].(string))
-		data.AutoCollect = types.BoolValue(subnetData["option_data_autocollect"].(string) == "1")
+	if !r.refresh(ctx, &data, &resp.Diagnostics) {
+		if !resp.Diagnostics.HasError() {
+			resp.State.RemoveResource(ctx)
+		}
+		return
 	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *KeaSubnetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	var data KeaSubnetResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
-	if resp.Diagnostics.HasError() { return }
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	payload := r.mapToPayload(ctx, &data)
-	jsonData, _ := json.Marshal(payload)
+	payload := r.mapToPayload(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.setItem(ctx, "kea/dhcpv4/set_subnet/"+data.ID.ValueString(), payload); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update subnet: %s", err))
+		return
+	}
+
+	applyKea(ctx, r.client)
 
-	url := fmt.Sprintf("%s/api/kea/dhcpv4/set_subnet/%s", r.client.Host, data.ID.ValueString())
-	r.doRequest(ctx, "POST", url, jsonData, &resp.Diagnostics)
-	
-	r.reconfigureService(ctx)
+	r.refresh(ctx, &data, &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *KeaSubnetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var data KeaSubnetResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
-	url := fmt.Sprintf("%s/api/kea/dhcpv4/del_subnet/%s", r.client.Host, data.ID.ValueString())
-	r.doRequest(ctx, "POST", url, nil, &resp.Diagnostics)
-	r.reconfigureService(ctx)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.post(ctx, "kea/dhcpv4/del_subnet/"+data.ID.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete subnet: %s", err))
+		return
+	}
+
+	applyKea(ctx, r.client)
 }
 
 func (r *KeaSubnetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
 
-func (r *KeaSubnetResource) mapToPayload(ctx context.Context, data *KeaSubnetResourceModel) map[string]interface{} {
-	subnet4 := map[string]interface{}{
-		"subnet": data.Subnet.ValueString(),
-	}
-
-	if !data.Pools.IsNull() { subnet4["pools"] = data.Pools.ValueString() }
-	if !data.Description.IsNull() { subnet4["description"] = data.Description.ValueString() }
-	
-	// Convert bool to OPNsense string "0" or "1"
-	if !data.AutoCollect.IsNull() && !data.AutoCollect.ValueBool() {
-		subnet4["option_data_autocollect"] = "0"
-	} else {
-		subnet4["option_data_autocollect"] = "1"
-	}
-
-	// Handle the options map - per XML model, option_data fields are direct strings
-	// Fields marked with <AsList>Y</AsList> accept comma-separated values
-	if !data.Option.IsNull() && !data.Option.IsUnknown() {
-		var optionMap map[string]string
-		data.Option.ElementsAs(ctx, &optionMap, false)
-		
-		optionData := make(map[string]interface{})
-		for k, v := range optionMap {
-			// Convert hyphenated names to underscores
-			key := strings.ReplaceAll(k, "-", "_")
-			// Strip spaces after commas: "10.0.1.1, 10.0.1.2" -> "10.0.1.1,10.0.1.2"
-			cleanValue := strings.ReplaceAll(v, ", ", ",")
-			// Direct string value - no nesting, no value/selected wrapper
-			optionData[key] = cleanValue
+// keaSubnetResourceModelV0 is the state of schema version 0, where option_data
+// was a map of OPNsense field names and pools a newline-separated string.
+type keaSubnetResourceModelV0 struct {
+	ID          types.String `tfsdk:"id"`
+	Subnet      types.String `tfsdk:"subnet"`
+	Pools       types.String `tfsdk:"pools"`
+	Option      types.Map    `tfsdk:"option_data"`
+	AutoCollect types.Bool   `tfsdk:"auto_collect"`
+	Description types.String `tfsdk:"description"`
+}
+
+func (r *KeaSubnetResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &schema.Schema{
+				Attributes: map[string]schema.Attribute{
+					"id":           schema.StringAttribute{Computed: true},
+					"subnet":       schema.StringAttribute{Required: true},
+					"pools":        schema.StringAttribute{Optional: true},
+					"description":  schema.StringAttribute{Optional: true},
+					"auto_collect": schema.BoolAttribute{Optional: true, Computed: true},
+					"option_data": schema.MapAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+					},
+				},
+			},
+			StateUpgrader: upgradeKeaSubnetStateV0,
+		},
+	}
+}
+
+// upgradeKeaSubnetStateV0 moves the option_data map onto the typed object and
+// splits the pools string into pool objects. Option keys were sent as OPNsense
+// field names, hyphens standing for underscores.
+func upgradeKeaSubnetStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	var prior keaSubnetResourceModelV0
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	options := map[string]interface{}{}
+	if !prior.Option.IsNull() && !prior.Option.IsUnknown() {
+		values := map[string]string{}
+		resp.Diagnostics.Append(prior.Option.ElementsAs(ctx, &values, false)...)
+		for key, value := range values {
+			key = strings.ReplaceAll(key, "-", "_")
+			if !slices.Contains(keaOptionKeys, key) {
+				resp.Diagnostics.AddWarning("Option Dropped",
+					fmt.Sprintf("option_data key %q of subnet %s has no counterpart in the typed option_data and was dropped from state.",
+						key, prior.Subnet.ValueString()))
+				continue
+			}
+			options[key] = value
+		}
+	}
+
+	for _, pool := range splitKeaPools(prior.Pools.ValueString()) {
+		if _, err := parseKeaPool(pool); err != nil {
+			resp.Diagnostics.AddWarning("Invalid Pool",
+				fmt.Sprintf("Pool %q of subnet %s can't be parsed (%s); fix it in the configuration.",
+					pool, prior.Subnet.ValueString(), err))
 		}
-		subnet4["option_data"] = optionData
 	}
 
+	autoCollect := prior.AutoCollect
+	if autoCollect.IsNull() || autoCollect.IsUnknown() {
+		autoCollect = types.BoolValue(true)
+	}
 
-	return map[string]interface{}{"subnet4": subnet4}
+	data := KeaSubnetResourceModel{
+		ID:          prior.ID,
+		Subnet:      prior.Subnet,
+		Pools:       keaPoolsFromAPI(ctx, types.ListNull(types.ObjectType{AttrTypes: keaPoolAttrTypes}), prior.Pools.ValueString(), &resp.Diagnostics),
+		Option:      keaOptionsFromAPI(ctx, types.ObjectNull(keaOptionDataAttrTypes), options, &resp.Diagnostics),
+		AutoCollect: autoCollect,
+		Description: prior.Description,
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
-func (r *KeaSubnetResource) doRequest(ctx context.Context, method, url string, body []byte, diags *diag.Diagnostics) []byte {
-	var reader io.Reader
-	if body != nil { reader = strings.NewReader(string(body)) }
-	req, _ := http.NewRequestWithContext(ctx, method, url, reader)
-	req.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	// Only set Content-Type if we have a body
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
+func (r *KeaSubnetResource) mapToPayload(ctx context.Context, data *KeaSubnetResourceModel, diags *diag.Diagnostics) map[string]interface{} {
+	subnet4 := map[string]interface{}{
+		"subnet":                  data.Subnet.ValueString(),
+		"pools":                   keaPoolsPayload(ctx, data.Pools, diags),
+		"description":             data.Description.ValueString(),
+		"option_data_autocollect": boolString(data.AutoCollect.ValueBool()),
+		"option_data":             keaOptionsPayload(ctx, data.Option, diags),
 	}
-	
-	httpResp, err := r.client.client.Do(req)
+
+	return map[string]interface{}{"subnet4": subnet4}
+}
+
+// refresh reads the subnet back into data, returning false when it no longer exists.
+func (r *KeaSubnetResource) refresh(ctx context.Context, data *KeaSubnetResourceModel, diags *diag.Diagnostics) bool {
+	subnet, err := r.client.getItem(ctx, "kea/dhcpv4/get_subnet/"+data.ID.ValueString(), "subnet4")
 	if err != nil {
-		diags.AddError("Client Error", err.Error())
-		return nil
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read subnet: %s", err))
+		return false
 	}
-	defer httpResp.Body.Close()
-	respBody, _ := io.ReadAll(httpResp.Body)
-	
-	if httpResp.StatusCode != 200 {
-		diags.AddError("API Error", fmt.Sprintf("Status %d: %s", httpResp.StatusCode, string(respBody)))
-		return nil
+	if subnet == nil {
+		return false
 	}
-	return respBody
+
+	data.Subnet = types.StringValue(stringField(subnet, "subnet"))
+	data.Pools = keaPoolsFromAPI(ctx, data.Pools, stringField(subnet, "pools"), diags)
+	data.Description = stringFromAPI(data.Description, stringField(subnet, "description"))
+	data.AutoCollect = types.BoolValue(boolField(subnet, "option_data_autocollect"))
+	data.Option = keaOptionsFromAPI(ctx, data.Option, subnet["option_data"], diags)
+
+	return true
 }
 
-func (r *KeaSubnetResource) reconfigureService(ctx context.Context) {
-	url := fmt.Sprintf("%s/api/kea/service/reconfigure", r.client.Host)
-	req, _ := http.NewRequestWithContext(ctx, "POST", url, nil)
-	req.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	r.client.client.Do(req)
-}
\ No newline at end of file
+// applyKea reconfigures the Kea service so saved changes take effect. A failed
+// reconfigure is logged, the configuration itself is already stored.
+func applyKea(ctx context.Context, client *Client) {
+	if err := client.post(ctx, "kea/service/reconfigure"); err != nil {
+		tflog.Warn(ctx, "Failed to reconfigure Kea", map[string]any{"error": err.Error()})
+	}
+}
@@ -0,0 +1,504 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &KeaSubnet6Resource{}
+var _ resource.ResourceWithImportState = &KeaSubnet6Resource{}
+var _ resource.ResourceWithValidateConfig = &KeaSubnet6Resource{}
+var _ resource.ResourceWithModifyPlan = &KeaSubnet6Resource{}
+
+func NewKeaSubnet6Resource() resource.Resource {
+	return &KeaSubnet6Resource{}
+}
+
+// KeaSubnet6Resource manages a Kea DHCPv6 subnet together with its prefix
+// delegation pools, which OPNsense stores as separate items.
+type KeaSubnet6Resource struct {
+	client *Client
+}
+
+type KeaSubnet6ResourceModel struct {
+	ID          types.String `tfsdk:"id"`
+	Subnet      types.String `tfsdk:"subnet"`
+	Interface   types.String `tfsdk:"interface"`
+	Pools       types.List   `tfsdk:"pools"`
+	PDPools     types.List   `tfsdk:"pd_pools"`
+	Option      types.Object `tfsdk:"option_data"`
+	Description types.String `tfsdk:"description"`
+}
+
+// keaPDPoolModel is a prefix delegation pool of a DHCPv6 subnet.
+type keaPDPoolModel struct {
+	Prefix          types.String `tfsdk:"prefix"`
+	DelegatedLength types.Int64  `tfsdk:"delegated_length"`
+	Description     types.String `tfsdk:"description"`
+}
+
+var keaPDPoolAttrTypes = map[string]attr.Type{
+	"prefix":           types.StringType,
+	"delegated_length": types.Int64Type,
+	"description":      types.StringType,
+}
+
+func (r *KeaSubnet6Resource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kea_subnet6"
+}
+
+func (r *KeaSubnet6Resource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages Kea DHCPv6 subnets and their prefix delegation pools in OPNsense",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Subnet UUID",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"subnet": schema.StringAttribute{
+				MarkdownDescription: "IPv6 network in CIDR notation (e.g., '2001:db8:10::/64')",
+				Required:            true,
+			},
+			"interface": schema.StringAttribute{
+				MarkdownDescription: "Interface the subnet is served on (e.g., 'lan'). Needed when clients are not on a directly matching network",
+				Optional:            true,
+			},
+			"pools": keaPoolsSchema("Dynamic address pools. Each must lie inside `subnet` and not overlap other pools or reserved addresses"),
+			"pd_pools": schema.ListNestedAttribute{
+				MarkdownDescription: "Prefix delegation pools handed out to requesting routers",
+				Optional:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"prefix": schema.StringAttribute{
+							MarkdownDescription: "Delegated prefix in CIDR notation (e.g., '2001:db8:100::/48')",
+							Required:            true,
+						},
+						"delegated_length": schema.Int64Attribute{
+							MarkdownDescription: "Prefix length handed to each client (e.g., 56)",
+							Required:            true,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "Description of the pool",
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"option_data": keaOption6DataSchema("DHCPv6 options handed out to clients of this subnet"),
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Description of the subnet",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (r *KeaSubnet6Resource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+// ValidateConfig checks the subnet, pools, prefix delegation pools and options.
+func (r *KeaSubnet6Resource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data KeaSubnet6ResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Subnet.IsNull() && !data.Subnet.IsUnknown() {
+		if p, err := netip.ParsePrefix(data.Subnet.ValueString()); err != nil || !p.Addr().Is6() {
+			resp.Diagnostics.AddAttributeError(path.Root("subnet"), "Invalid Subnet",
+				fmt.Sprintf("%q is not an IPv6 network in CIDR notation.", data.Subnet.ValueString()))
+		}
+	}
+
+	validateKeaPools(ctx, data.Pools, data.Subnet, path.Root("pools"), &resp.Diagnostics)
+	validateKeaOptions6(ctx, data.Option, path.Root("option_data"), &resp.Diagnostics)
+
+	if data.PDPools.IsNull() || data.PDPools.IsUnknown() {
+		return
+	}
+	var pools []keaPDPoolModel
+	resp.Diagnostics.Append(data.PDPools.ElementsAs(ctx, &pools, false)...)
+
+	prefixes := map[int]netip.Prefix{}
+	for i, pool := range pools {
+		if pool.Prefix.IsUnknown() {
+			continue
+		}
+		p, err := netip.ParsePrefix(pool.Prefix.ValueString())
+		if err != nil || !p.Addr().Is6() {
+			resp.Diagnostics.AddAttributeError(path.Root("pd_pools").AtListIndex(i).AtName("prefix"), "Invalid Prefix",
+				fmt.Sprintf("%q is not an IPv6 prefix in CIDR notation.", pool.Prefix.ValueString()))
+			continue
+		}
+		prefixes[i] = p.Masked()
+
+		if pool.DelegatedLength.IsUnknown() {
+			continue
+		}
+		if l := pool.DelegatedLength.ValueInt64(); l < int64(p.Bits()) || l > 128 {
+			resp.Diagnostics.AddAttributeError(path.Root("pd_pools").AtListIndex(i).AtName("delegated_length"), "Invalid Delegated Length",
+				fmt.Sprintf("delegated_length must be between the prefix length (%d) and 128.", p.Bits()))
+		}
+	}
+
+	for i := range pools {
+		for j := i + 1; j < len(pools); j++ {
+			a, okA := prefixes[i]
+			b, okB := prefixes[j]
+			if okA && okB && a.Overlaps(b) {
+				resp.Diagnostics.AddAttributeError(path.Root("pd_pools").AtListIndex(j).AtName("prefix"), "Overlapping Prefixes",
+					fmt.Sprintf("Prefix %s overlaps prefix %s.", b, a))
+			}
+		}
+	}
+}
+
+// ModifyPlan rejects new or changed pools containing addresses that are
+// already reserved.
+func (r *KeaSubnet6Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check on destroy or before the provider is configured
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var data KeaSubnet6ResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Unchanged pools were checked when they were planned
+	if !req.State.Raw.IsNull() {
+		var pools types.List
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("pools"), &pools)...)
+		if resp.Diagnostics.HasError() || data.Pools.Equal(pools) {
+			return
+		}
+	}
+
+	checkKeaPoolReservations(ctx, r.client, "dhcpv6", data.Pools, path.Root("pools"), &resp.Diagnostics)
+}
+
+func (r *KeaSubnet6Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data KeaSubnet6ResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	payload := r.mapToPayload(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid, err := r.client.addItem(ctx, "kea/dhcpv6/add_subnet", payload)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create subnet: %s", err))
+		return
+	}
+	data.ID = types.StringValue(uuid)
+
+	// Save the subnet before syncing pools, so a failure doesn't orphan it
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), data.ID)...)
+
+	r.syncPDPools(ctx, &data, &resp.Diagnostics)
+	applyKea(ctx, r.client)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KeaSubnet6Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data KeaSubnet6ResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !r.refresh(ctx, &data, &resp.Diagnostics) {
+		if !resp.Diagnostics.HasError() {
+			resp.State.RemoveResource(ctx)
+		}
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KeaSubnet6Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data KeaSubnet6ResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	payload := r.mapToPayload(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.setItem(ctx, "kea/dhcpv6/set_subnet/"+data.ID.ValueString(), payload); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update subnet: %s", err))
+		return
+	}
+
+	r.syncPDPools(ctx, &data, &resp.Diagnostics)
+	applyKea(ctx, r.client)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KeaSubnet6Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data KeaSubnet6ResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Prefix delegation pools reference the subnet, remove them first
+	pools, err := r.pdPools(ctx, &data)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list prefix delegation pools: %s", err))
+		return
+	}
+	for _, pool := range pools {
+		if err := r.client.post(ctx, "kea/dhcpv6/del_pd_pool/"+pool.uuid); err != nil && !isNotFound(err) {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete prefix delegation pool %s: %s", pool.prefix, err))
+			return
+		}
+	}
+
+	if err := r.client.post(ctx, "kea/dhcpv6/del_subnet/"+data.ID.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete subnet: %s", err))
+		return
+	}
+
+	applyKea(ctx, r.client)
+}
+
+func (r *KeaSubnet6Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func (r *KeaSubnet6Resource) mapToPayload(ctx context.Context, data *KeaSubnet6ResourceModel, diags *diag.Diagnostics) map[string]interface{} {
+	subnet6 := map[string]interface{}{
+		"subnet":      data.Subnet.ValueString(),
+		"interface":   data.Interface.ValueString(),
+		"pools":       keaPoolsPayload(ctx, data.Pools, diags),
+		"description": data.Description.ValueString(),
+		"option_data": keaOptions6Payload(ctx, data.Option, diags),
+	}
+
+	return map[string]interface{}{"subnet6": subnet6}
+}
+
+// refresh reads the subnet and its prefix delegation pools back into data,
+// returning false when the subnet no longer exists.
+func (r *KeaSubnet6Resource) refresh(ctx context.Context, data *KeaSubnet6ResourceModel, diags *diag.Diagnostics) bool {
+	subnet, err := r.client.getItem(ctx, "kea/dhcpv6/get_subnet/"+data.ID.ValueString(), "subnet6")
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read subnet: %s", err))
+		return false
+	}
+	if subnet == nil {
+		return false
+	}
+
+	data.Subnet = types.StringValue(stringField(subnet, "subnet"))
+	data.Interface = stringFromAPI(data.Interface, selectedOption(subnet["interface"]))
+	data.Pools = keaPoolsFromAPI(ctx, data.Pools, stringField(subnet, "pools"), diags)
+	data.Description = stringFromAPI(data.Description, stringField(subnet, "description"))
+	data.Option = keaOptions6FromAPI(ctx, data.Option, subnet["option_data"], diags)
+
+	pools, err := r.pdPools(ctx, data)
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to list prefix delegation pools: %s", err))
+		return false
+	}
+
+	poolType := types.ObjectType{AttrTypes: keaPDPoolAttrTypes}
+	if len(pools) == 0 && data.PDPools.IsNull() {
+		data.PDPools = types.ListNull(poolType)
+		return true
+	}
+
+	// Keep the configured order, pools added elsewhere go last
+	var configured []keaPDPoolModel
+	if !data.PDPools.IsNull() && !data.PDPools.IsUnknown() {
+		diags.Append(data.PDPools.ElementsAs(ctx, &configured, false)...)
+	}
+	byPrefix := make(map[string]keaPDPool, len(pools))
+	for _, pool := range pools {
+		byPrefix[pool.prefix] = pool
+	}
+
+	models := make([]keaPDPoolModel, 0, len(pools))
+	add := func(pool keaPDPool, prefix, description types.String) {
+		models = append(models, keaPDPoolModel{
+			Prefix:          prefix,
+			DelegatedLength: types.Int64Value(pool.delegatedLength),
+			Description:     stringFromAPI(description, pool.description),
+		})
+		delete(byPrefix, pool.prefix)
+	}
+	for _, c := range configured {
+		if pool, ok := byPrefix[normalizePrefix(c.Prefix.ValueString())]; ok {
+			add(pool, c.Prefix, c.Description)
+		}
+	}
+	for _, pool := range pools {
+		if _, ok := byPrefix[pool.prefix]; ok {
+			add(pool, types.StringValue(pool.prefix), types.StringNull())
+		}
+	}
+
+	list, d := types.ListValueFrom(ctx, poolType, models)
+	diags.Append(d...)
+	data.PDPools = list
+
+	return true
+}
+
+// keaPDPool is a prefix delegation pool as stored by OPNsense.
+type keaPDPool struct {
+	uuid            string
+	prefix          string // CIDR, normalized
+	delegatedLength int64
+	description     string
+}
+
+// pdPools lists the prefix delegation pools belonging to the subnet. Search
+// rows render the subnet relation by its network, older versions by UUID.
+func (r *KeaSubnet6Resource) pdPools(ctx context.Context, data *KeaSubnet6ResourceModel) ([]keaPDPool, error) {
+	rows, err := r.client.searchItems(ctx, "kea/dhcpv6/search_pd_pool")
+	if err != nil {
+		return nil, err
+	}
+
+	subnet := normalizePrefix(data.Subnet.ValueString())
+	var pools []keaPDPool
+	for _, row := range rows {
+		owner := stringField(row, "subnet")
+		if owner != data.ID.ValueString() && normalizePrefix(owner) != subnet {
+			continue
+		}
+		prefixLen, _ := int64Field(row, "prefix_len")
+		delegatedLen, _ := int64Field(row, "delegated_len")
+		pools = append(pools, keaPDPool{
+			uuid:            stringField(row, "uuid"),
+			prefix:          normalizePrefix(fmt.Sprintf("%s/%d", stringField(row, "prefix"), prefixLen)),
+			delegatedLength: delegatedLen,
+			description:     stringField(row, "description"),
+		})
+	}
+	return pools, nil
+}
+
+// syncPDPools makes the prefix delegation pools of the subnet match data:
+// pools are matched by prefix, changed ones updated, missing ones added and
+// the rest removed.
+func (r *KeaSubnet6Resource) syncPDPools(ctx context.Context, data *KeaSubnet6ResourceModel, diags *diag.Diagnostics) {
+	existing, err := r.pdPools(ctx, data)
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to list prefix delegation pools: %s", err))
+		return
+	}
+	byPrefix := make(map[string]keaPDPool, len(existing))
+	for _, pool := range existing {
+		byPrefix[pool.prefix] = pool
+	}
+
+	var desired []keaPDPoolModel
+	if !data.PDPools.IsNull() && !data.PDPools.IsUnknown() {
+		diags.Append(data.PDPools.ElementsAs(ctx, &desired, false)...)
+	}
+
+	for _, pool := range desired {
+		p, err := netip.ParsePrefix(pool.Prefix.ValueString())
+		if err != nil {
+			diags.AddError("Invalid Prefix", fmt.Sprintf("%q is not an IPv6 prefix: %s", pool.Prefix.ValueString(), err))
+			return
+		}
+		p = p.Masked()
+		payload := map[string]interface{}{
+			"pd_pool": map[string]interface{}{
+				"subnet":        data.ID.ValueString(),
+				"prefix":        p.Addr().String(),
+				"prefix_len":    int64String(int64(p.Bits())),
+				"delegated_len": int64String(pool.DelegatedLength.ValueInt64()),
+				"description":   pool.Description.ValueString(),
+			},
+		}
+
+		if current, ok := byPrefix[p.String()]; ok {
+			delete(byPrefix, p.String())
+			if current.delegatedLength == pool.DelegatedLength.ValueInt64() && current.description == pool.Description.ValueString() {
+				continue
+			}
+			if err := r.client.setItem(ctx, "kea/dhcpv6/set_pd_pool/"+current.uuid, payload); err != nil {
+				diags.AddError("Client Error", fmt.Sprintf("Unable to update prefix delegation pool %s: %s", p, err))
+				return
+			}
+			continue
+		}
+
+		if _, err := r.client.addItem(ctx, "kea/dhcpv6/add_pd_pool", payload); err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to create prefix delegation pool %s: %s", p, err))
+			return
+		}
+	}
+
+	for _, pool := range byPrefix {
+		if err := r.client.post(ctx, "kea/dhcpv6/del_pd_pool/"+pool.uuid); err != nil && !isNotFound(err) {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to delete prefix delegation pool %s: %s", pool.prefix, err))
+			return
+		}
+	}
+}
+
+// normalizePrefix returns the canonical form of a CIDR (masked, lower case),
+// or v unchanged when it doesn't parse.
+func normalizePrefix(v string) string {
+	p, err := netip.ParsePrefix(strings.TrimSpace(v))
+	if err != nil {
+		return v
+	}
+	return p.Masked().String()
+}
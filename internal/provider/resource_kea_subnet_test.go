@@ -0,0 +1,191 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+func TestUpgradeKeaSubnetStateV0(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     map[string]string // nil for a null option_data
+		pools       types.String
+		autoCollect types.Bool
+
+		wantRouters     []string
+		wantDNS         []string
+		wantDomain      string
+		wantOptionsNull bool
+		wantPools       []string // keaPoolString form, nil for null pools
+		wantAutoCollect bool
+		wantWarnings    int
+	}{
+		{
+			name: "hyphenated keys and comma lists",
+			options: map[string]string{
+				"routers":             "10.0.10.1",
+				"domain-name-servers": "10.0.20.11, 10.0.20.22",
+				"domain_name":         "mgmt.local",
+			},
+			pools:           types.StringNull(),
+			autoCollect:     types.BoolValue(false),
+			wantRouters:     []string{"10.0.10.1"},
+			wantDNS:         []string{"10.0.20.11", "10.0.20.22"},
+			wantDomain:      "mgmt.local",
+			wantAutoCollect: false,
+		},
+		{
+			name: "unknown key dropped",
+			options: map[string]string{
+				"routers":         "10.0.10.1",
+				"next-server-foo": "10.0.10.5",
+			},
+			pools:           types.StringNull(),
+			autoCollect:     types.BoolValue(true),
+			wantRouters:     []string{"10.0.10.1"},
+			wantAutoCollect: true,
+			wantWarnings:    1,
+		},
+		{
+			name:            "pools string",
+			pools:           types.StringValue("10.0.10.100-10.0.10.149\n10.0.10.160/28"),
+			autoCollect:     types.BoolValue(true),
+			wantOptionsNull: true,
+			wantPools:       []string{"10.0.10.100-10.0.10.149", "10.0.10.160/28"},
+			wantAutoCollect: true,
+		},
+		{
+			name:            "spaced range",
+			pools:           types.StringValue("10.0.10.100 - 10.0.10.149"),
+			autoCollect:     types.BoolValue(true),
+			wantOptionsNull: true,
+			wantPools:       []string{"10.0.10.100-10.0.10.149"},
+			wantAutoCollect: true,
+		},
+		{
+			name:            "unparseable pool kept",
+			pools:           types.StringValue("10.0.10.100-"),
+			autoCollect:     types.BoolValue(true),
+			wantOptionsNull: true,
+			wantPools:       []string{"10.0.10.100-"},
+			wantAutoCollect: true,
+			wantWarnings:    1,
+		},
+		{
+			name:            "null auto_collect defaults to true",
+			options:         map[string]string{},
+			pools:           types.StringNull(),
+			autoCollect:     types.BoolNull(),
+			wantOptionsNull: true,
+			wantAutoCollect: true,
+		},
+	}
+
+	ctx := context.Background()
+	r := &KeaSubnetResource{}
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	upgrader := r.UpgradeState(ctx)[0]
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			option := types.MapNull(types.StringType)
+			if tt.options != nil {
+				var d diag.Diagnostics
+				option, d = types.MapValueFrom(ctx, types.StringType, tt.options)
+				if d.HasError() {
+					t.Fatal(d)
+				}
+			}
+
+			prior := tfsdk.State{Schema: *upgrader.PriorSchema}
+			if d := prior.Set(ctx, &keaSubnetResourceModelV0{
+				ID:          types.StringValue("7c1e8a2e-0000-4000-8000-000000000001"),
+				Subnet:      types.StringValue("10.0.10.0/24"),
+				Pools:       tt.pools,
+				Option:      option,
+				AutoCollect: tt.autoCollect,
+				Description: types.StringValue("Management VLAN"),
+			}); d.HasError() {
+				t.Fatal(d)
+			}
+
+			req := resource.UpgradeStateRequest{State: &prior}
+			resp := resource.UpgradeStateResponse{State: tfsdk.State{Schema: schemaResp.Schema}}
+			upgrader.StateUpgrader(ctx, req, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatal(resp.Diagnostics)
+			}
+			if got := resp.Diagnostics.WarningsCount(); got != tt.wantWarnings {
+				t.Errorf("warnings = %d, want %d: %v", got, tt.wantWarnings, resp.Diagnostics)
+			}
+
+			var data KeaSubnetResourceModel
+			if d := resp.State.Get(ctx, &data); d.HasError() {
+				t.Fatal(d)
+			}
+
+			if data.ID.ValueString() != "7c1e8a2e-0000-4000-8000-000000000001" || data.Subnet.ValueString() != "10.0.10.0/24" ||
+				data.Description.ValueString() != "Management VLAN" {
+				t.Errorf("id, subnet or description changed: %v %v %v", data.ID, data.Subnet, data.Description)
+			}
+			if data.AutoCollect.IsNull() || data.AutoCollect.ValueBool() != tt.wantAutoCollect {
+				t.Errorf("auto_collect = %v, want %t", data.AutoCollect, tt.wantAutoCollect)
+			}
+
+			var diags diag.Diagnostics
+			var pools []string
+			if !data.Pools.IsNull() {
+				var models []keaPoolModel
+				diags.Append(data.Pools.ElementsAs(ctx, &models, false)...)
+				for _, pool := range models {
+					pools = append(pools, keaPoolString(pool))
+				}
+			}
+			if !reflect.DeepEqual(pools, tt.wantPools) {
+				t.Errorf("pools = %q, want %q", pools, tt.wantPools)
+			}
+
+			if data.Option.IsNull() != tt.wantOptionsNull {
+				t.Fatalf("option_data = %v, want null=%t", data.Option, tt.wantOptionsNull)
+			}
+			if data.Option.IsNull() {
+				return
+			}
+			var opts keaOptionDataModel
+			diags.Append(data.Option.As(ctx, &opts, basetypes.ObjectAsOptions{})...)
+			if diags.HasError() {
+				t.Fatal(diags)
+			}
+
+			routers := listValues(ctx, opts.Routers, &diags)
+			dns := listValues(ctx, opts.DomainNameServers, &diags)
+			if len(routers) == 0 {
+				routers = nil
+			}
+			if len(dns) == 0 {
+				dns = nil
+			}
+			if !reflect.DeepEqual(routers, tt.wantRouters) {
+				t.Errorf("routers = %q, want %q", routers, tt.wantRouters)
+			}
+			if !reflect.DeepEqual(dns, tt.wantDNS) {
+				t.Errorf("domain_name_servers = %q, want %q", dns, tt.wantDNS)
+			}
+			if opts.DomainName.ValueString() != tt.wantDomain {
+				t.Errorf("domain_name = %v, want %q", opts.DomainName, tt.wantDomain)
+			}
+			if !opts.NTPServers.IsNull() {
+				t.Errorf("ntp_servers = %v, want null", opts.NTPServers)
+			}
+		})
+	}
+}
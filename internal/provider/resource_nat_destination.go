@@ -2,23 +2,26 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var _ resource.Resource = &NatDestinationResource{}
 var _ resource.ResourceWithImportState = &NatDestinationResource{}
+var _ resource.ResourceWithValidateConfig = &NatDestinationResource{}
+var _ resource.ResourceWithModifyPlan = &NatDestinationResource{}
 
 func NewNatDestinationResource() resource.Resource {
 	return &NatDestinationResource{}
@@ -29,23 +32,30 @@ type NatDestinationResource struct {
 }
 
 type NatDestinationResourceModel struct {
-	ID              types.String `tfsdk:"id"`
-	Enabled         types.Bool   `tfsdk:"enabled"`
-	Sequence        types.Int64  `tfsdk:"sequence"`
-	Interface       types.String `tfsdk:"interface"`
-	Protocol        types.String `tfsdk:"protocol"`
-	IPProtocol      types.String `tfsdk:"ip_protocol"`
-	SourceNet       types.String `tfsdk:"source_net"`
-	SourcePort      types.String `tfsdk:"source_port"`
-	SourceNot       types.Bool   `tfsdk:"source_not"`
-	DestinationNet  types.String `tfsdk:"destination_net"`
-	DestinationPort types.String `tfsdk:"destination_port"`
-	DestinationNot  types.Bool   `tfsdk:"destination_not"`
-	TargetIP        types.String `tfsdk:"target_ip"`
-	TargetPort      types.String `tfsdk:"target_port"`
-	Description     types.String `tfsdk:"description"`
-	Log             types.Bool   `tfsdk:"log"`
-	NATReflection   types.String `tfsdk:"nat_reflection"`
+	ID                    types.String `tfsdk:"id"`
+	Enabled               types.Bool   `tfsdk:"enabled"`
+	NoRdr                 types.Bool   `tfsdk:"no_rdr"`
+	Sequence              types.Int64  `tfsdk:"sequence"`
+	Interface             types.String `tfsdk:"interface"`
+	Protocol              types.String `tfsdk:"protocol"`
+	IPProtocol            types.String `tfsdk:"ip_protocol"`
+	SourceNet             types.String `tfsdk:"source_net"`
+	SourcePort            types.String `tfsdk:"source_port"`
+	SourceNot             types.Bool   `tfsdk:"source_not"`
+	DestinationNet        types.String `tfsdk:"destination_net"`
+	DestinationPort       types.String `tfsdk:"destination_port"`
+	DestinationNot        types.Bool   `tfsdk:"destination_not"`
+	TargetIP              types.String `tfsdk:"target_ip"`
+	TargetPort            types.String `tfsdk:"target_port"`
+	PoolOptions           types.String `tfsdk:"pool_options"`
+	FilterRuleAssociation types.String `tfsdk:"filter_rule_association"`
+	AssociatedRuleID      types.String `tfsdk:"associated_filter_rule_id"`
+	Tag                   types.String `tfsdk:"tag"`
+	Tagged                types.String `tfsdk:"tagged"`
+	Description           types.String `tfsdk:"description"`
+	Log                   types.Bool   `tfsdk:"log"`
+	NATReflection         types.String `tfsdk:"nat_reflection"`
+	Categories            types.List   `tfsdk:"categories"`
 }
 
 func (r *NatDestinationResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -68,10 +78,19 @@ func (r *NatDestinationResource) Schema(ctx context.Context, req resource.Schema
 				MarkdownDescription: "Enable this NAT rule (default: true)",
 				Optional:            true,
 				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"no_rdr": schema.BoolAttribute{
+				MarkdownDescription: "Do not redirect matching traffic (exception to a later port forward). `target_ip` is not required",
+				Optional:            true,
 			},
 			"sequence": schema.Int64Attribute{
-				MarkdownDescription: "Rule sequence/priority (lower = higher priority)",
+				MarkdownDescription: "Rule sequence/priority (lower = higher priority). Assigned by OPNsense when unset",
 				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
 			},
 			"interface": schema.StringAttribute{
 				MarkdownDescription: "Interface (e.g., 'wan')",
@@ -82,11 +101,14 @@ func (r *NatDestinationResource) Schema(ctx context.Context, req resource.Schema
 				Required:            true,
 			},
 			"ip_protocol": schema.StringAttribute{
-				MarkdownDescription: "IP protocol: 'inet' (IPv4), 'inet6' (IPv6), or 'inet46' (both)",
+				MarkdownDescription: "IP protocol: 'inet' (IPv4, default), 'inet6' (IPv6), or 'inet46' (both)",
 				Optional:            true,
+				Validators: []validator.String{
+					stringOneOf("inet", "inet6", "inet46"),
+				},
 			},
 			"source_net": schema.StringAttribute{
-				MarkdownDescription: "Source network/address",
+				MarkdownDescription: "Source network/address. Default is 'any'",
 				Optional:            true,
 			},
 			"source_port": schema.StringAttribute{
@@ -98,7 +120,7 @@ func (r *NatDestinationResource) Schema(ctx context.Context, req resource.Schema
 				Optional:            true,
 			},
 			"destination_net": schema.StringAttribute{
-				MarkdownDescription: "Destination network/address (e.g., 'wanip', 'any')",
+				MarkdownDescription: "Destination network/address (e.g., 'wanip'). Default is 'any'",
 				Optional:            true,
 			},
 			"destination_port": schema.StringAttribute{
@@ -110,12 +132,38 @@ func (r *NatDestinationResource) Schema(ctx context.Context, req resource.Schema
 				Optional:            true,
 			},
 			"target_ip": schema.StringAttribute{
-				MarkdownDescription: "Internal target IP address or alias (required)",
-				Required:            true,
+				MarkdownDescription: "Internal target IP address or alias. Required unless `no_rdr` is set",
+				Optional:            true,
 			},
 			"target_port": schema.StringAttribute{
-				MarkdownDescription: "Internal target port (required)",
-				Required:            true,
+				MarkdownDescription: "Internal target port",
+				Optional:            true,
+			},
+			"pool_options": schema.StringAttribute{
+				MarkdownDescription: "Pool option when `target_ip` is an alias or network: 'round-robin', 'round-robin sticky-address', 'random', 'random sticky-address', 'source-hash', 'bitmask'",
+				Optional:            true,
+				Validators: []validator.String{
+					stringOneOf("round-robin", "round-robin sticky-address", "random", "random sticky-address", "source-hash", "bitmask"),
+				},
+			},
+			"filter_rule_association": schema.StringAttribute{
+				MarkdownDescription: "Filter rule association: 'none' (default, traffic needs a matching firewall rule), 'pass' (pass matching traffic without a filter rule) or 'associated' (create and own a linked pass rule)",
+				Optional:            true,
+				Validators: []validator.String{
+					stringOneOf("none", "pass", "associated"),
+				},
+			},
+			"associated_filter_rule_id": schema.StringAttribute{
+				MarkdownDescription: "UUID of the filter rule owned by this port forward when `filter_rule_association` is 'associated'",
+				Computed:            true,
+			},
+			"tag": schema.StringAttribute{
+				MarkdownDescription: "Tag matching packets, usable as `tagged` in firewall rules",
+				Optional:            true,
+			},
+			"tagged": schema.StringAttribute{
+				MarkdownDescription: "Only match packets carrying this tag",
+				Optional:            true,
 			},
 			"description": schema.StringAttribute{
 				MarkdownDescription: "Description for this NAT rule",
@@ -126,9 +174,13 @@ func (r *NatDestinationResource) Schema(ctx context.Context, req resource.Schema
 				Optional:            true,
 			},
 			"nat_reflection": schema.StringAttribute{
-				MarkdownDescription: "NAT reflection: 'enable', 'purenat', 'disable'",
+				MarkdownDescription: "NAT reflection: 'enable', 'purenat', 'disable'. Default follows the global setting",
 				Optional:            true,
+				Validators: []validator.String{
+					stringOneOf("enable", "purenat", "disable"),
+				},
 			},
+			"categories": natCategoriesAttribute(),
 		},
 	}
 }
@@ -150,315 +202,447 @@ func (r *NatDestinationResource) Configure(ctx context.Context, req resource.Con
 	r.client = client
 }
 
-func (r *NatDestinationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+// ValidateConfig requires a redirect target unless the rule is a no-RDR
+// exception, and rejects associated filter rules on no-RDR exceptions: the
+// filter rule would pass traffic to the (empty) target.
+func (r *NatDestinationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
 	var data NatDestinationResourceModel
-	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Build NAT destination rule payload
-	// ACTUAL field names from API (using dots and hyphens!):
-	natData := map[string]interface{}{
-		"destination": map[string]interface{}{
-			"interface":          data.Interface.ValueString(),
-			"protocol":           data.Protocol.ValueString(),
-			"destination.port":   data.DestinationPort.ValueString(), // Uses dot!
-			"target":             data.TargetIP.ValueString(),
-			"local-port":         data.TargetPort.ValueString(), // Uses hyphen!
-		},
-	}
-
-	// Disabled field (0 = enabled, 1 = disabled - inverted!)
-	if !data.Enabled.IsNull() {
-		if data.Enabled.ValueBool() {
-			natData["destination"].(map[string]interface{})["disabled"] = "0"
-		} else {
-			natData["destination"].(map[string]interface{})["disabled"] = "1"
-		}
-	} else {
-		natData["destination"].(map[string]interface{})["disabled"] = "0"
-	}
-
-	// Sequence
-	if !data.Sequence.IsNull() {
-		natData["destination"].(map[string]interface{})["sequence"] = fmt.Sprintf("%d", data.Sequence.ValueInt64())
+	if data.NoRdr.ValueBool() && data.FilterRuleAssociation.ValueString() == "associated" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("filter_rule_association"),
+			"Invalid Attribute Combination",
+			"`filter_rule_association = \"associated\"` can't be used with `no_rdr`, a no-RDR exception has no target to pass traffic to.",
+		)
 	}
 
-	// IP Protocol
-	if !data.IPProtocol.IsNull() {
-		natData["destination"].(map[string]interface{})["ipprotocol"] = data.IPProtocol.ValueString()
+	if data.NoRdr.IsUnknown() || data.TargetIP.IsUnknown() {
+		return
 	}
 
-	// Optional source fields
-	if !data.SourceNet.IsNull() {
-		natData["destination"].(map[string]interface{})["source.network"] = data.SourceNet.ValueString() // Uses dot!
+	if !data.NoRdr.ValueBool() && data.TargetIP.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("target_ip"),
+			"Missing Attribute",
+			"`target_ip` is required unless `no_rdr` is set.",
+		)
 	}
+}
 
-	if !data.SourcePort.IsNull() {
-		natData["destination"].(map[string]interface{})["source.port"] = data.SourcePort.ValueString() // Uses dot!
+// ModifyPlan keeps the associated filter rule UUID known while the rule stays
+// associated, so unrelated changes don't show it as "known after apply".
+func (r *NatDestinationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
 	}
 
-	if !data.SourceNot.IsNull() && data.SourceNot.ValueBool() {
-		natData["destination"].(map[string]interface{})["source.not"] = "1" // Uses dot!
+	var plan NatDestinationResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || plan.FilterRuleAssociation.IsUnknown() {
+		return
 	}
 
-	// Optional destination fields
-	if !data.DestinationNet.IsNull() {
-		natData["destination"].(map[string]interface{})["destination.network"] = data.DestinationNet.ValueString() // Uses dot!
+	associated := types.StringUnknown()
+	if plan.FilterRuleAssociation.ValueString() != "associated" {
+		associated = types.StringNull()
+	} else if !req.State.Raw.IsNull() {
+		var state NatDestinationResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if !state.AssociatedRuleID.IsNull() {
+			associated = state.AssociatedRuleID
+		}
 	}
 
-	if !data.DestinationNot.IsNull() && data.DestinationNot.ValueBool() {
-		natData["destination"].(map[string]interface{})["destination.not"] = "1" // Uses dot!
-	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("associated_filter_rule_id"), associated)...)
+}
 
-	// Description
-	if !data.Description.IsNull() {
-		natData["destination"].(map[string]interface{})["descr"] = data.Description.ValueString()
+func (r *NatDestinationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data NatDestinationResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	// Log
-	if !data.Log.IsNull() && data.Log.ValueBool() {
-		natData["destination"].(map[string]interface{})["log"] = "1"
+	data.AssociatedRuleID = types.StringNull()
+	r.syncFilterRule(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	// NAT Reflection
-	if !data.NATReflection.IsNull() {
-		natData["destination"].(map[string]interface{})["natreflection"] = data.NATReflection.ValueString()
+	payload := r.mapToPayload(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	jsonData, _ := json.Marshal(natData)
-	tflog.Debug(ctx, "Creating NAT destination rule", map[string]any{"payload": string(jsonData)})
-
-	// API endpoint
-	url := fmt.Sprintf("%s/api/firewall/d_nat/add_rule", r.client.Host)
-	httpReq, _ := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonData)))
-	httpReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	httpReq.Header.Set("Content-Type", "application/json")
+	tflog.Debug(ctx, "Creating NAT destination rule", map[string]any{"payload": payload})
 
-	httpResp, err := r.client.client.Do(httpReq)
+	uuid, err := r.client.addItem(ctx, "firewall/d_nat/add_rule", payload)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create NAT rule: %s", err))
+		// Don't leave the filter rule behind without its port forward
+		r.removeFilterRule(ctx, data.AssociatedRuleID.ValueString(), &resp.Diagnostics)
 		return
 	}
-	defer httpResp.Body.Close()
+	data.ID = types.StringValue(uuid)
 
-	body, _ := io.ReadAll(httpResp.Body)
-	tflog.Debug(ctx, "NAT rule created", map[string]any{"response": string(body)})
+	applyNat(ctx, r.client, "d_nat")
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response: %s", err))
-		return
-	}
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
 
-	// Check for failed result
-	if resultStr, ok := result["result"].(string); ok && resultStr == "failed" {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("API returned failed: %s", string(body)))
+func (r *NatDestinationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data NatDestinationResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Extract UUID
-	if uuid, ok := result["uuid"].(string); ok {
-		data.ID = types.StringValue(uuid)
-	} else if resultStr, ok := result["result"].(string); ok {
-		data.ID = types.StringValue(resultStr)
-	} else {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("No UUID returned from API: %s", string(body)))
+	if !r.refresh(ctx, &data, &resp.Diagnostics) {
+		if !resp.Diagnostics.HasError() {
+			resp.State.RemoveResource(ctx)
+		}
 		return
 	}
 
-	// Apply the configuration
-	applyURL := fmt.Sprintf("%s/api/firewall/d_nat/apply", r.client.Host)
-	applyReq, _ := http.NewRequestWithContext(ctx, "POST", applyURL, nil)
-	applyReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-
-	applyResp, err := r.client.client.Do(applyReq)
-	if err != nil {
-		tflog.Warn(ctx, "Failed to apply NAT configuration", map[string]any{"error": err.Error()})
-	} else {
-		defer applyResp.Body.Close()
-	}
-
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
-func (r *NatDestinationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var data NatDestinationResourceModel
-	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+func (r *NatDestinationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state NatDestinationResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	url := fmt.Sprintf("%s/api/firewall/d_nat/get_rule/%s", r.client.Host, data.ID.ValueString())
-	httpReq, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
-	httpReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-
-	httpResp, err := r.client.client.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read NAT rule: %s", err))
+	data.AssociatedRuleID = state.AssociatedRuleID
+	r.syncFilterRule(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	defer httpResp.Body.Close()
 
-	if httpResp.StatusCode == 404 {
-		resp.State.RemoveResource(ctx)
+	payload := r.mapToPayload(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	body, _ := io.ReadAll(httpResp.Body)
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response: %s", err))
+	if err := r.client.setItem(ctx, "firewall/d_nat/set_rule/"+data.ID.ValueString(), payload); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update NAT rule: %s", err))
 		return
 	}
 
-	// Parse from search_rule format
-	if iface, ok := result["interface"].(string); ok {
-		data.Interface = types.StringValue(iface)
-	}
-	if proto, ok := result["protocol"].(string); ok {
-		data.Protocol = types.StringValue(proto)
-	}
-	if target, ok := result["target"].(string); ok {
-		data.TargetIP = types.StringValue(target)
-	}
-	if descr, ok := result["descr"].(string); ok {
-		data.Description = types.StringValue(descr)
-	}
-	if disabled, ok := result["disabled"].(string); ok {
-		data.Enabled = types.BoolValue(disabled == "0") // Inverted!
+	applyNat(ctx, r.client, "d_nat")
+
+	// The link is gone, the filter rule owned until now goes with it
+	if data.FilterRuleAssociation.ValueString() != "associated" && !state.AssociatedRuleID.IsNull() {
+		r.removeFilterRule(ctx, state.AssociatedRuleID.ValueString(), &resp.Diagnostics)
+		data.AssociatedRuleID = types.StringNull()
 	}
 
+	r.refresh(ctx, &data, &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
-func (r *NatDestinationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+func (r *NatDestinationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var data NatDestinationResourceModel
-	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	natData := map[string]interface{}{
-		"destination": map[string]interface{}{
-			"interface":        data.Interface.ValueString(),
-			"protocol":         data.Protocol.ValueString(),
-			"destination.port": data.DestinationPort.ValueString(),
-			"target":           data.TargetIP.ValueString(),
-			"local-port":       data.TargetPort.ValueString(),
-		},
+	if err := r.client.post(ctx, "firewall/d_nat/del_rule/"+data.ID.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete NAT rule: %s", err))
+		return
 	}
 
-	if !data.Enabled.IsNull() {
-		if data.Enabled.ValueBool() {
-			natData["destination"].(map[string]interface{})["disabled"] = "0"
-		} else {
-			natData["destination"].(map[string]interface{})["disabled"] = "1"
-		}
-	}
+	applyNat(ctx, r.client, "d_nat")
 
-	if !data.Sequence.IsNull() {
-		natData["destination"].(map[string]interface{})["sequence"] = fmt.Sprintf("%d", data.Sequence.ValueInt64())
+	if !data.AssociatedRuleID.IsNull() {
+		r.removeFilterRule(ctx, data.AssociatedRuleID.ValueString(), &resp.Diagnostics)
 	}
+}
 
-	if !data.IPProtocol.IsNull() {
-		natData["destination"].(map[string]interface{})["ipprotocol"] = data.IPProtocol.ValueString()
-	}
+func (r *NatDestinationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
 
-	if !data.SourceNet.IsNull() {
-		natData["destination"].(map[string]interface{})["source.network"] = data.SourceNet.ValueString()
+func (r *NatDestinationResource) mapToPayload(ctx context.Context, data *NatDestinationResourceModel, diags *diag.Diagnostics) map[string]interface{} {
+	// "disabled" is inverted compared to the other NAT models
+	rule := map[string]interface{}{
+		"disabled":   boolString(!data.Enabled.ValueBool()),
+		"nordr":      boolString(data.NoRdr.ValueBool()),
+		"interface":  data.Interface.ValueString(),
+		"ipprotocol": valueOrDefault(data.IPProtocol, "inet"),
+		"protocol":   data.Protocol.ValueString(),
+		"source": map[string]interface{}{
+			"network": valueOrDefault(data.SourceNet, "any"),
+			"port":    data.SourcePort.ValueString(),
+			"not":     boolString(data.SourceNot.ValueBool()),
+		},
+		"destination": map[string]interface{}{
+			"network": valueOrDefault(data.DestinationNet, "any"),
+			"port":    data.DestinationPort.ValueString(),
+			"not":     boolString(data.DestinationNot.ValueBool()),
+		},
+		"target":        data.TargetIP.ValueString(),
+		"local-port":    data.TargetPort.ValueString(),
+		"poolopts":      data.PoolOptions.ValueString(),
+		"tag":           data.Tag.ValueString(),
+		"tagged":        data.Tagged.ValueString(),
+		"log":           boolString(data.Log.ValueBool()),
+		"natreflection": data.NATReflection.ValueString(),
+		"descr":         data.Description.ValueString(),
 	}
 
-	if !data.SourcePort.IsNull() {
-		natData["destination"].(map[string]interface{})["source.port"] = data.SourcePort.ValueString()
+	if !data.Sequence.IsNull() && !data.Sequence.IsUnknown() {
+		rule["sequence"] = int64String(data.Sequence.ValueInt64())
 	}
 
-	if !data.SourceNot.IsNull() && data.SourceNot.ValueBool() {
-		natData["destination"].(map[string]interface{})["source.not"] = "1"
+	switch data.FilterRuleAssociation.ValueString() {
+	case "pass":
+		rule["associated-rule-id"] = "pass"
+	case "associated":
+		rule["associated-rule-id"] = data.AssociatedRuleID.ValueString()
+	default:
+		rule["associated-rule-id"] = ""
 	}
 
-	if !data.DestinationNet.IsNull() {
-		natData["destination"].(map[string]interface{})["destination.network"] = data.DestinationNet.ValueString()
-	}
+	rule["category"] = r.client.categoriesPayload(ctx, data.Categories, diags)
+
+	return map[string]interface{}{"rule": rule}
+}
 
-	if !data.DestinationNot.IsNull() && data.DestinationNot.ValueBool() {
-		natData["destination"].(map[string]interface{})["destination.not"] = "1"
+// refresh reads the rule back into data, returning false when it no longer exists.
+func (r *NatDestinationResource) refresh(ctx context.Context, data *NatDestinationResourceModel, diags *diag.Diagnostics) bool {
+	rule, err := r.client.getItem(ctx, "firewall/d_nat/get_rule/"+data.ID.ValueString(), "rule")
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read NAT rule: %s", err))
+		return false
+	}
+	if rule == nil {
+		return false
+	}
+
+	data.Enabled = types.BoolValue(!boolField(rule, "disabled"))
+	data.NoRdr = boolFromAPI(data.NoRdr, boolField(rule, "nordr"))
+	if seq, ok := int64Field(rule, "sequence"); ok {
+		data.Sequence = types.Int64Value(seq)
+	}
+	data.Interface = types.StringValue(strings.Join(selectedOptions(rule["interface"]), ","))
+	data.IPProtocol = stringFromAPIDefault(data.IPProtocol, selectedOption(rule["ipprotocol"]), "inet")
+	data.Protocol = types.StringValue(selectedOption(rule["protocol"]))
+
+	data.SourceNet = stringFromAPIDefault(data.SourceNet, natAddressField(rule, "source", "network"), "any")
+	data.SourcePort = stringFromAPI(data.SourcePort, natAddressField(rule, "source", "port"))
+	data.SourceNot = boolFromAPI(data.SourceNot, natAddressField(rule, "source", "not") == "1")
+	data.DestinationNet = stringFromAPIDefault(data.DestinationNet, natAddressField(rule, "destination", "network"), "any")
+	data.DestinationPort = types.StringValue(natAddressField(rule, "destination", "port"))
+	data.DestinationNot = boolFromAPI(data.DestinationNot, natAddressField(rule, "destination", "not") == "1")
+
+	data.TargetIP = stringFromAPI(data.TargetIP, stringField(rule, "target"))
+	data.TargetPort = stringFromAPI(data.TargetPort, stringField(rule, "local-port"))
+	data.PoolOptions = stringFromAPI(data.PoolOptions, selectedOption(rule["poolopts"]))
+	data.Tag = stringFromAPI(data.Tag, stringField(rule, "tag"))
+	data.Tagged = stringFromAPI(data.Tagged, stringField(rule, "tagged"))
+	data.Log = boolFromAPI(data.Log, boolField(rule, "log"))
+	data.NATReflection = stringFromAPI(data.NATReflection, selectedOption(rule["natreflection"]))
+	data.Description = stringFromAPI(data.Description, stringField(rule, "descr"))
+
+	association := "none"
+	data.AssociatedRuleID = types.StringNull()
+	switch linked := stringField(rule, "associated-rule-id"); {
+	case linked == "pass":
+		association = "pass"
+	case linked != "":
+		association = "associated"
+		r.refreshFilterRule(ctx, data, linked, diags)
+	}
+	data.FilterRuleAssociation = stringFromAPIDefault(data.FilterRuleAssociation, association, "none")
+
+	data.Categories = r.client.categoriesFromAPI(ctx, data.Categories, rule["category"], diags)
+
+	return true
+}
+
+// filterRulePayload builds the pass rule matching the translated traffic: the
+// filter sees packets after redirection, so it targets target_ip/target_port.
+func (r *NatDestinationResource) filterRulePayload(data *NatDestinationResourceModel) map[string]interface{} {
+	port := data.TargetPort.ValueString()
+	if port == "" {
+		port = data.DestinationPort.ValueString()
 	}
 
-	if !data.Description.IsNull() {
-		natData["destination"].(map[string]interface{})["descr"] = data.Description.ValueString()
+	description := "NAT " + data.Description.ValueString()
+	if data.Description.ValueString() == "" {
+		description = "NAT " + data.DestinationPort.ValueString() + " to " + data.TargetIP.ValueString()
 	}
 
-	if !data.Log.IsNull() && data.Log.ValueBool() {
-		natData["destination"].(map[string]interface{})["log"] = "1"
+	return map[string]interface{}{
+		"rule": map[string]interface{}{
+			"enabled":          boolString(data.Enabled.ValueBool()),
+			"action":           "pass",
+			"quick":            "1",
+			"interface":        data.Interface.ValueString(),
+			"direction":        "in",
+			"ipprotocol":       valueOrDefault(data.IPProtocol, "inet"),
+			"protocol":         data.Protocol.ValueString(),
+			"source_net":       valueOrDefault(data.SourceNet, "any"),
+			"source_port":      data.SourcePort.ValueString(),
+			"source_not":       boolString(data.SourceNot.ValueBool()),
+			"destination_net":  data.TargetIP.ValueString(),
+			"destination_port": port,
+			"log":              boolString(data.Log.ValueBool()),
+			"description":      description,
+		},
 	}
+}
 
-	if !data.NATReflection.IsNull() {
-		natData["destination"].(map[string]interface{})["natreflection"] = data.NATReflection.ValueString()
+// syncFilterRule creates or updates the owned filter rule when the port forward
+// is associated. Removing a rule that is no longer wanted is left to the caller,
+// after the port forward stopped referencing it.
+func (r *NatDestinationResource) syncFilterRule(ctx context.Context, data *NatDestinationResourceModel, diags *diag.Diagnostics) {
+	if data.FilterRuleAssociation.ValueString() != "associated" {
+		return
 	}
 
-	jsonData, _ := json.Marshal(natData)
+	payload := r.filterRulePayload(data)
+	if id := data.AssociatedRuleID.ValueString(); id != "" {
+		if err := r.client.setItem(ctx, "firewall/filter/setRule/"+id, payload); err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to update associated filter rule: %s", err))
+			return
+		}
+	} else {
+		uuid, err := r.client.addItem(ctx, "firewall/filter/addRule", payload)
+		if err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to create associated filter rule: %s", err))
+			return
+		}
+		data.AssociatedRuleID = types.StringValue(uuid)
+	}
 
-	url := fmt.Sprintf("%s/api/firewall/d_nat/set_rule/%s", r.client.Host, data.ID.ValueString())
-	httpReq, _ := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonData)))
-	httpReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
-	httpReq.Header.Set("Content-Type", "application/json")
+	applyFilter(ctx, r.client)
+}
 
-	httpResp, err := r.client.client.Do(httpReq)
+// refreshFilterRule records the linked filter rule, unless it was deleted
+// outside of Terraform, in which case the next apply recreates it.
+func (r *NatDestinationResource) refreshFilterRule(ctx context.Context, data *NatDestinationResourceModel, id string, diags *diag.Diagnostics) {
+	rule, err := r.client.getItem(ctx, "firewall/filter/getRule/"+id, "rule")
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update NAT rule: %s", err))
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read associated filter rule: %s", err))
+		return
+	}
+	if rule == nil {
+		tflog.Warn(ctx, "Associated filter rule no longer exists", map[string]any{"uuid": id})
 		return
 	}
-	defer httpResp.Body.Close()
+	data.AssociatedRuleID = types.StringValue(id)
+}
 
-	// Apply
-	applyURL := fmt.Sprintf("%s/api/firewall/d_nat/apply", r.client.Host)
-	applyReq, _ := http.NewRequestWithContext(ctx, "POST", applyURL, nil)
-	applyReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
+func (r *NatDestinationResource) removeFilterRule(ctx context.Context, id string, diags *diag.Diagnostics) {
+	if id == "" {
+		return
+	}
+	if err := r.client.post(ctx, "firewall/filter/delRule/"+id); err != nil && !isNotFound(err) {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to delete associated filter rule %s: %s", id, err))
+		return
+	}
+	applyFilter(ctx, r.client)
+}
 
-	applyResp, err := r.client.client.Do(applyReq)
-	if err != nil {
-		tflog.Warn(ctx, "Failed to apply NAT configuration", map[string]any{"error": err.Error()})
-	} else {
-		defer applyResp.Body.Close()
+// natAddressField returns a field of the source/destination container of a
+// d_nat rule. get_rule nests them ({"source": {"network": ...}}), search_rule
+// rows flatten them to "source.network".
+func natAddressField(rule map[string]interface{}, container, key string) string {
+	if nested, ok := rule[container].(map[string]interface{}); ok {
+		// Network fields may be rendered as option lists
+		if _, isOptions := nested[key].(map[string]interface{}); isOptions {
+			return strings.Join(selectedOptions(nested[key]), ",")
+		}
+		return stringField(nested, key)
 	}
+	return stringField(rule, container+"."+key)
+}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+// applyFilter applies pending filter rule changes, logging failures like applyNat.
+func applyFilter(ctx context.Context, client *Client) {
+	if err := client.post(ctx, "firewall/filter/apply"); err != nil {
+		tflog.Warn(ctx, "Failed to apply firewall configuration", map[string]any{"error": err.Error()})
+	}
 }
 
-func (r *NatDestinationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var data NatDestinationResourceModel
-	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
-	if resp.Diagnostics.HasError() {
-		return
+// applyNat applies pending changes of a NAT controller (d_nat, source_nat,
+// one_to_one, npt). A failed apply leaves the saved configuration in place
+// for the next apply, so it is logged rather than failing the resource.
+func applyNat(ctx context.Context, client *Client, controller string) {
+	if err := client.post(ctx, "firewall/"+controller+"/apply"); err != nil {
+		tflog.Warn(ctx, "Failed to apply NAT configuration", map[string]any{
+			"controller": controller,
+			"error":      err.Error(),
+		})
+	}
+}
+
+// natCategoriesAttribute is the categories attribute of the NAT rule resources.
+func natCategoriesAttribute() schema.ListAttribute {
+	return schema.ListAttribute{
+		MarkdownDescription: "List of category names or UUIDs. Names that don't exist yet are created as automatic categories",
+		Optional:            true,
+		ElementType:         types.StringType,
 	}
+}
 
-	url := fmt.Sprintf("%s/api/firewall/d_nat/del_rule/%s", r.client.Host, data.ID.ValueString())
-	httpReq, _ := http.NewRequestWithContext(ctx, "POST", url, nil)
-	httpReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
+// natRuleFields points at the attributes shared by the models of the
+// source_nat, one_to_one and npt rule resources.
+type natRuleFields struct {
+	Enabled     *types.Bool
+	Sequence    *types.Int64
+	Log         *types.Bool
+	Description *types.String
+	Categories  *types.List
+}
 
-	httpResp, err := r.client.client.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete NAT rule: %s", err))
-		return
+// natRulePayload adds the shared fields to a rule payload. sequence is left
+// out when unset so OPNsense assigns one.
+func (c *Client) natRulePayload(ctx context.Context, rule map[string]interface{}, f natRuleFields, diags *diag.Diagnostics) map[string]interface{} {
+	rule["enabled"] = boolString(f.Enabled.ValueBool())
+	rule["log"] = boolString(f.Log.ValueBool())
+	rule["description"] = f.Description.ValueString()
+	if !f.Sequence.IsNull() && !f.Sequence.IsUnknown() {
+		rule["sequence"] = int64String(f.Sequence.ValueInt64())
 	}
-	defer httpResp.Body.Close()
+	rule["categories"] = c.categoriesPayload(ctx, *f.Categories, diags)
 
-	// Apply
-	applyURL := fmt.Sprintf("%s/api/firewall/d_nat/apply", r.client.Host)
-	applyReq, _ := http.NewRequestWithContext(ctx, "POST", applyURL, nil)
-	applyReq.SetBasicAuth(r.client.ApiKey, r.client.ApiSecret)
+	return map[string]interface{}{"rule": rule}
+}
 
-	applyResp, err := r.client.client.Do(applyReq)
+// natRule fetches a rule of a NAT controller and refreshes the shared fields
+// from it. It returns nil when the rule no longer exists or could not be
+// read, the latter reported in diags.
+func (c *Client) natRule(ctx context.Context, controller, id, label string, f natRuleFields, diags *diag.Diagnostics) map[string]interface{} {
+	rule, err := c.getItem(ctx, "firewall/"+controller+"/get_rule/"+id, "rule")
 	if err != nil {
-		tflog.Warn(ctx, "Failed to apply NAT configuration", map[string]any{"error": err.Error()})
-	} else {
-		defer applyResp.Body.Close()
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read %s: %s", label, err))
+		return nil
+	}
+	if rule == nil {
+		return nil
 	}
-}
 
-func (r *NatDestinationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
-}
\ No newline at end of file
+	*f.Enabled = types.BoolValue(boolField(rule, "enabled"))
+	if seq, ok := int64Field(rule, "sequence"); ok {
+		*f.Sequence = types.Int64Value(seq)
+	}
+	*f.Log = boolFromAPI(*f.Log, boolField(rule, "log"))
+	*f.Description = stringFromAPI(*f.Description, stringField(rule, "description"))
+	*f.Categories = c.categoriesFromAPI(ctx, *f.Categories, rule["categories"], diags)
+
+	return rule
+}
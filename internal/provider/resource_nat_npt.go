@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &NatNptResource{}
+var _ resource.ResourceWithImportState = &NatNptResource{}
+
+func NewNatNptResource() resource.Resource {
+	return &NatNptResource{}
+}
+
+// NatNptResource manages IPv6 network prefix translation (NPTv6) rules.
+type NatNptResource struct {
+	client *Client
+}
+
+type NatNptResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	Enabled        types.Bool   `tfsdk:"enabled"`
+	Sequence       types.Int64  `tfsdk:"sequence"`
+	Interface      types.String `tfsdk:"interface"`
+	SourceNet      types.String `tfsdk:"source_net"`
+	DestinationNet types.String `tfsdk:"destination_net"`
+	TrackInterface types.String `tfsdk:"track_interface"`
+	Log            types.Bool   `tfsdk:"log"`
+	Categories     types.List   `tfsdk:"categories"`
+	Description    types.String `tfsdk:"description"`
+}
+
+// fields returns the attributes shared with the other NAT rule resources.
+func (data *NatNptResourceModel) fields() natRuleFields {
+	return natRuleFields{&data.Enabled, &data.Sequence, &data.Log, &data.Description, &data.Categories}
+}
+
+func (r *NatNptResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_nat_npt"
+}
+
+func (r *NatNptResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages NPTv6 (IPv6 network prefix translation) rules in OPNsense",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "NAT rule UUID",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				MarkdownDescription: "Enable this NAT rule (default: true)",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"sequence": schema.Int64Attribute{
+				MarkdownDescription: "Rule sequence/priority (lower = higher priority). Assigned by OPNsense when unset",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"interface": schema.StringAttribute{
+				MarkdownDescription: "Interface (e.g., 'wan')",
+				Required:            true,
+			},
+			"source_net": schema.StringAttribute{
+				MarkdownDescription: "Internal IPv6 prefix (e.g., 'fd00:10::/64')",
+				Required:            true,
+			},
+			"destination_net": schema.StringAttribute{
+				MarkdownDescription: "External IPv6 prefix, same length as `source_net`. Leave unset with `track_interface`",
+				Optional:            true,
+			},
+			"track_interface": schema.StringAttribute{
+				MarkdownDescription: "Interface whose (dynamic) prefix is used as external prefix",
+				Optional:            true,
+			},
+			"log": schema.BoolAttribute{
+				MarkdownDescription: "Log packets matching this rule",
+				Optional:            true,
+			},
+			"categories": natCategoriesAttribute(),
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Description for this NAT rule",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (r *NatNptResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *NatNptResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data NatNptResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	payload := r.mapToPayload(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid, err := r.client.addItem(ctx, "firewall/npt/add_rule", payload)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create NPT rule: %s", err))
+		return
+	}
+	data.ID = types.StringValue(uuid)
+
+	applyNat(ctx, r.client, "npt")
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *NatNptResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data NatNptResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !r.refresh(ctx, &data, &resp.Diagnostics) {
+		if !resp.Diagnostics.HasError() {
+			resp.State.RemoveResource(ctx)
+		}
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *NatNptResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data NatNptResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	payload := r.mapToPayload(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.setItem(ctx, "firewall/npt/set_rule/"+data.ID.ValueString(), payload); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update NPT rule: %s", err))
+		return
+	}
+
+	applyNat(ctx, r.client, "npt")
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *NatNptResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data NatNptResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.post(ctx, "firewall/npt/del_rule/"+data.ID.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete NPT rule: %s", err))
+		return
+	}
+
+	applyNat(ctx, r.client, "npt")
+}
+
+func (r *NatNptResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func (r *NatNptResource) mapToPayload(ctx context.Context, data *NatNptResourceModel, diags *diag.Diagnostics) map[string]interface{} {
+	rule := map[string]interface{}{
+		"interface":       data.Interface.ValueString(),
+		"source_net":      data.SourceNet.ValueString(),
+		"destination_net": data.DestinationNet.ValueString(),
+		"trackif":         data.TrackInterface.ValueString(),
+	}
+
+	return r.client.natRulePayload(ctx, rule, data.fields(), diags)
+}
+
+// refresh reads the rule back into data, returning false when it no longer exists.
+func (r *NatNptResource) refresh(ctx context.Context, data *NatNptResourceModel, diags *diag.Diagnostics) bool {
+	rule := r.client.natRule(ctx, "npt", data.ID.ValueString(), "NPT rule", data.fields(), diags)
+	if rule == nil {
+		return false
+	}
+
+	data.Interface = types.StringValue(strings.Join(selectedOptions(rule["interface"]), ","))
+	data.SourceNet = types.StringValue(stringField(rule, "source_net"))
+	data.DestinationNet = stringFromAPI(data.DestinationNet, stringField(rule, "destination_net"))
+	data.TrackInterface = stringFromAPI(data.TrackInterface, selectedOption(rule["trackif"]))
+
+	return true
+}
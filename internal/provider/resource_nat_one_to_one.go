@@ -0,0 +1,267 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &NatOneToOneResource{}
+var _ resource.ResourceWithImportState = &NatOneToOneResource{}
+
+func NewNatOneToOneResource() resource.Resource {
+	return &NatOneToOneResource{}
+}
+
+type NatOneToOneResource struct {
+	client *Client
+}
+
+type NatOneToOneResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	Enabled        types.Bool   `tfsdk:"enabled"`
+	Sequence       types.Int64  `tfsdk:"sequence"`
+	Interface      types.String `tfsdk:"interface"`
+	Type           types.String `tfsdk:"type"`
+	External       types.String `tfsdk:"external"`
+	SourceNet      types.String `tfsdk:"source_net"`
+	SourceNot      types.Bool   `tfsdk:"source_not"`
+	DestinationNet types.String `tfsdk:"destination_net"`
+	DestinationNot types.Bool   `tfsdk:"destination_not"`
+	NATReflection  types.String `tfsdk:"nat_reflection"`
+	Log            types.Bool   `tfsdk:"log"`
+	Categories     types.List   `tfsdk:"categories"`
+	Description    types.String `tfsdk:"description"`
+}
+
+// fields returns the attributes shared with the other NAT rule resources.
+func (data *NatOneToOneResourceModel) fields() natRuleFields {
+	return natRuleFields{&data.Enabled, &data.Sequence, &data.Log, &data.Description, &data.Categories}
+}
+
+func (r *NatOneToOneResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_nat_one_to_one"
+}
+
+func (r *NatOneToOneResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages One-to-One (1:1) NAT rules in OPNsense",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "NAT rule UUID",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				MarkdownDescription: "Enable this NAT rule (default: true)",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"sequence": schema.Int64Attribute{
+				MarkdownDescription: "Rule sequence/priority (lower = higher priority). Assigned by OPNsense when unset",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"interface": schema.StringAttribute{
+				MarkdownDescription: "Interface (e.g., 'wan')",
+				Required:            true,
+			},
+			"type": schema.StringAttribute{
+				MarkdownDescription: "NAT type: 'binat' (bidirectional, default) or 'nat' (outbound only)",
+				Optional:            true,
+				Validators: []validator.String{
+					stringOneOf("binat", "nat"),
+				},
+			},
+			"external": schema.StringAttribute{
+				MarkdownDescription: "External address or subnet the internal address/subnet is mapped to",
+				Required:            true,
+			},
+			"source_net": schema.StringAttribute{
+				MarkdownDescription: "Internal address or subnet (e.g., '10.0.50.10/32')",
+				Required:            true,
+			},
+			"source_not": schema.BoolAttribute{
+				MarkdownDescription: "Invert source match",
+				Optional:            true,
+			},
+			"destination_net": schema.StringAttribute{
+				MarkdownDescription: "Restrict the mapping to this destination. Default is 'any'",
+				Optional:            true,
+			},
+			"destination_not": schema.BoolAttribute{
+				MarkdownDescription: "Invert destination match",
+				Optional:            true,
+			},
+			"nat_reflection": schema.StringAttribute{
+				MarkdownDescription: "NAT reflection: 'enable' or 'disable'. Default follows the global setting",
+				Optional:            true,
+				Validators: []validator.String{
+					stringOneOf("enable", "disable"),
+				},
+			},
+			"log": schema.BoolAttribute{
+				MarkdownDescription: "Log packets matching this rule",
+				Optional:            true,
+			},
+			"categories": natCategoriesAttribute(),
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Description for this NAT rule",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (r *NatOneToOneResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *NatOneToOneResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data NatOneToOneResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	payload := r.mapToPayload(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid, err := r.client.addItem(ctx, "firewall/one_to_one/add_rule", payload)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create one-to-one NAT rule: %s", err))
+		return
+	}
+	data.ID = types.StringValue(uuid)
+
+	applyNat(ctx, r.client, "one_to_one")
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *NatOneToOneResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data NatOneToOneResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !r.refresh(ctx, &data, &resp.Diagnostics) {
+		if !resp.Diagnostics.HasError() {
+			resp.State.RemoveResource(ctx)
+		}
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *NatOneToOneResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data NatOneToOneResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	payload := r.mapToPayload(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.setItem(ctx, "firewall/one_to_one/set_rule/"+data.ID.ValueString(), payload); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update one-to-one NAT rule: %s", err))
+		return
+	}
+
+	applyNat(ctx, r.client, "one_to_one")
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *NatOneToOneResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data NatOneToOneResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.post(ctx, "firewall/one_to_one/del_rule/"+data.ID.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete one-to-one NAT rule: %s", err))
+		return
+	}
+
+	applyNat(ctx, r.client, "one_to_one")
+}
+
+func (r *NatOneToOneResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func (r *NatOneToOneResource) mapToPayload(ctx context.Context, data *NatOneToOneResourceModel, diags *diag.Diagnostics) map[string]interface{} {
+	rule := map[string]interface{}{
+		"interface":       data.Interface.ValueString(),
+		"type":            valueOrDefault(data.Type, "binat"),
+		"external":        data.External.ValueString(),
+		"source_net":      data.SourceNet.ValueString(),
+		"source_not":      boolString(data.SourceNot.ValueBool()),
+		"destination_net": valueOrDefault(data.DestinationNet, "any"),
+		"destination_not": boolString(data.DestinationNot.ValueBool()),
+		"natreflection":   data.NATReflection.ValueString(),
+	}
+
+	return r.client.natRulePayload(ctx, rule, data.fields(), diags)
+}
+
+// refresh reads the rule back into data, returning false when it no longer exists.
+func (r *NatOneToOneResource) refresh(ctx context.Context, data *NatOneToOneResourceModel, diags *diag.Diagnostics) bool {
+	rule := r.client.natRule(ctx, "one_to_one", data.ID.ValueString(), "one-to-one NAT rule", data.fields(), diags)
+	if rule == nil {
+		return false
+	}
+
+	data.Interface = types.StringValue(strings.Join(selectedOptions(rule["interface"]), ","))
+	data.Type = stringFromAPIDefault(data.Type, selectedOption(rule["type"]), "binat")
+	data.External = types.StringValue(stringField(rule, "external"))
+	data.SourceNet = types.StringValue(stringField(rule, "source_net"))
+	data.SourceNot = boolFromAPI(data.SourceNot, boolField(rule, "source_not"))
+	data.DestinationNet = stringFromAPIDefault(data.DestinationNet, stringField(rule, "destination_net"), "any")
+	data.DestinationNot = boolFromAPI(data.DestinationNot, boolField(rule, "destination_not"))
+	data.NATReflection = stringFromAPI(data.NATReflection, selectedOption(rule["natreflection"]))
+
+	return true
+}
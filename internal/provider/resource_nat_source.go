@@ -0,0 +1,309 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &NatSourceResource{}
+var _ resource.ResourceWithImportState = &NatSourceResource{}
+
+func NewNatSourceResource() resource.Resource {
+	return &NatSourceResource{}
+}
+
+type NatSourceResource struct {
+	client *Client
+}
+
+type NatSourceResourceModel struct {
+	ID              types.String `tfsdk:"id"`
+	Enabled         types.Bool   `tfsdk:"enabled"`
+	NoNat           types.Bool   `tfsdk:"no_nat"`
+	Sequence        types.Int64  `tfsdk:"sequence"`
+	Interface       types.String `tfsdk:"interface"`
+	IPProtocol      types.String `tfsdk:"ip_protocol"`
+	Protocol        types.String `tfsdk:"protocol"`
+	SourceNet       types.String `tfsdk:"source_net"`
+	SourcePort      types.String `tfsdk:"source_port"`
+	SourceNot       types.Bool   `tfsdk:"source_not"`
+	DestinationNet  types.String `tfsdk:"destination_net"`
+	DestinationPort types.String `tfsdk:"destination_port"`
+	DestinationNot  types.Bool   `tfsdk:"destination_not"`
+	Target          types.String `tfsdk:"target"`
+	TargetPort      types.String `tfsdk:"target_port"`
+	PoolOptions     types.String `tfsdk:"pool_options"`
+	StaticPort      types.Bool   `tfsdk:"static_port"`
+	Log             types.Bool   `tfsdk:"log"`
+	Categories      types.List   `tfsdk:"categories"`
+	Description     types.String `tfsdk:"description"`
+}
+
+// fields returns the attributes shared with the other NAT rule resources.
+func (data *NatSourceResourceModel) fields() natRuleFields {
+	return natRuleFields{&data.Enabled, &data.Sequence, &data.Log, &data.Description, &data.Categories}
+}
+
+func (r *NatSourceResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_nat_source"
+}
+
+func (r *NatSourceResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages Source NAT (Outbound NAT) rules in OPNsense",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "NAT rule UUID",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				MarkdownDescription: "Enable this NAT rule (default: true)",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"no_nat": schema.BoolAttribute{
+				MarkdownDescription: "Do not NAT matching traffic (exception to broader rules below)",
+				Optional:            true,
+			},
+			"sequence": schema.Int64Attribute{
+				MarkdownDescription: "Rule sequence/priority (lower = higher priority). Assigned by OPNsense when unset",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"interface": schema.StringAttribute{
+				MarkdownDescription: "Outbound interface (e.g., 'wan')",
+				Required:            true,
+			},
+			"ip_protocol": schema.StringAttribute{
+				MarkdownDescription: "IP protocol: 'inet' (IPv4) or 'inet6' (IPv6). Default is 'inet'",
+				Optional:            true,
+				Validators: []validator.String{
+					stringOneOf("inet", "inet6"),
+				},
+			},
+			"protocol": schema.StringAttribute{
+				MarkdownDescription: "Protocol (e.g., 'any', 'tcp', 'udp', 'tcp/udp'). Default is 'any'",
+				Optional:            true,
+			},
+			"source_net": schema.StringAttribute{
+				MarkdownDescription: "Source network/address or alias (e.g., 'lan', '10.0.10.0/24'). Default is 'any'",
+				Optional:            true,
+			},
+			"source_port": schema.StringAttribute{
+				MarkdownDescription: "Source port",
+				Optional:            true,
+			},
+			"source_not": schema.BoolAttribute{
+				MarkdownDescription: "Invert source match",
+				Optional:            true,
+			},
+			"destination_net": schema.StringAttribute{
+				MarkdownDescription: "Destination network/address or alias. Default is 'any'",
+				Optional:            true,
+			},
+			"destination_port": schema.StringAttribute{
+				MarkdownDescription: "Destination port",
+				Optional:            true,
+			},
+			"destination_not": schema.BoolAttribute{
+				MarkdownDescription: "Invert destination match",
+				Optional:            true,
+			},
+			"target": schema.StringAttribute{
+				MarkdownDescription: "Translation target: interface address (e.g., 'wanip'), IP address, alias, or network for a pool. Ignored with `no_nat`",
+				Optional:            true,
+			},
+			"target_port": schema.StringAttribute{
+				MarkdownDescription: "Translation port",
+				Optional:            true,
+			},
+			"pool_options": schema.StringAttribute{
+				MarkdownDescription: "Pool option when `target` is a network or alias: 'round-robin', 'round-robin sticky-address', 'random', 'random sticky-address', 'source-hash', 'bitmask'",
+				Optional:            true,
+				Validators: []validator.String{
+					stringOneOf("round-robin", "round-robin sticky-address", "random", "random sticky-address", "source-hash", "bitmask"),
+				},
+			},
+			"static_port": schema.BoolAttribute{
+				MarkdownDescription: "Keep the source port unchanged",
+				Optional:            true,
+			},
+			"log": schema.BoolAttribute{
+				MarkdownDescription: "Log packets matching this rule",
+				Optional:            true,
+			},
+			"categories": natCategoriesAttribute(),
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Description for this NAT rule",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (r *NatSourceResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *NatSourceResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data NatSourceResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	payload := r.mapToPayload(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	uuid, err := r.client.addItem(ctx, "firewall/source_nat/add_rule", payload)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create source NAT rule: %s", err))
+		return
+	}
+	data.ID = types.StringValue(uuid)
+
+	applyNat(ctx, r.client, "source_nat")
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *NatSourceResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data NatSourceResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !r.refresh(ctx, &data, &resp.Diagnostics) {
+		if !resp.Diagnostics.HasError() {
+			resp.State.RemoveResource(ctx)
+		}
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *NatSourceResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data NatSourceResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	payload := r.mapToPayload(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.setItem(ctx, "firewall/source_nat/set_rule/"+data.ID.ValueString(), payload); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update source NAT rule: %s", err))
+		return
+	}
+
+	applyNat(ctx, r.client, "source_nat")
+
+	r.refresh(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *NatSourceResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data NatSourceResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.post(ctx, "firewall/source_nat/del_rule/"+data.ID.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete source NAT rule: %s", err))
+		return
+	}
+
+	applyNat(ctx, r.client, "source_nat")
+}
+
+func (r *NatSourceResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func (r *NatSourceResource) mapToPayload(ctx context.Context, data *NatSourceResourceModel, diags *diag.Diagnostics) map[string]interface{} {
+	rule := map[string]interface{}{
+		"nonat":            boolString(data.NoNat.ValueBool()),
+		"interface":        data.Interface.ValueString(),
+		"ipprotocol":       valueOrDefault(data.IPProtocol, "inet"),
+		"protocol":         valueOrDefault(data.Protocol, "any"),
+		"source_net":       valueOrDefault(data.SourceNet, "any"),
+		"source_port":      data.SourcePort.ValueString(),
+		"source_not":       boolString(data.SourceNot.ValueBool()),
+		"destination_net":  valueOrDefault(data.DestinationNet, "any"),
+		"destination_port": data.DestinationPort.ValueString(),
+		"destination_not":  boolString(data.DestinationNot.ValueBool()),
+		"target":           data.Target.ValueString(),
+		"target_port":      data.TargetPort.ValueString(),
+		"poolopts":         data.PoolOptions.ValueString(),
+		"staticnatport":    boolString(data.StaticPort.ValueBool()),
+	}
+
+	return r.client.natRulePayload(ctx, rule, data.fields(), diags)
+}
+
+// refresh reads the rule back into data, returning false when it no longer exists.
+func (r *NatSourceResource) refresh(ctx context.Context, data *NatSourceResourceModel, diags *diag.Diagnostics) bool {
+	rule := r.client.natRule(ctx, "source_nat", data.ID.ValueString(), "source NAT rule", data.fields(), diags)
+	if rule == nil {
+		return false
+	}
+
+	data.NoNat = boolFromAPI(data.NoNat, boolField(rule, "nonat"))
+	data.Interface = types.StringValue(strings.Join(selectedOptions(rule["interface"]), ","))
+	data.IPProtocol = stringFromAPIDefault(data.IPProtocol, selectedOption(rule["ipprotocol"]), "inet")
+	data.Protocol = stringFromAPIDefault(data.Protocol, selectedOption(rule["protocol"]), "any")
+	data.SourceNet = stringFromAPIDefault(data.SourceNet, stringField(rule, "source_net"), "any")
+	data.SourcePort = stringFromAPI(data.SourcePort, stringField(rule, "source_port"))
+	data.SourceNot = boolFromAPI(data.SourceNot, boolField(rule, "source_not"))
+	data.DestinationNet = stringFromAPIDefault(data.DestinationNet, stringField(rule, "destination_net"), "any")
+	data.DestinationPort = stringFromAPI(data.DestinationPort, stringField(rule, "destination_port"))
+	data.DestinationNot = boolFromAPI(data.DestinationNot, boolField(rule, "destination_not"))
+	data.Target = stringFromAPI(data.Target, stringField(rule, "target"))
+	data.TargetPort = stringFromAPI(data.TargetPort, stringField(rule, "target_port"))
+	data.PoolOptions = stringFromAPI(data.PoolOptions, selectedOption(rule["poolopts"]))
+	data.StaticPort = boolFromAPI(data.StaticPort, boolField(rule, "staticnatport"))
+
+	return true
+}